@@ -0,0 +1,213 @@
+// Package anthropictest provides a fake Messages API server, so the
+// anthropic provider can be exercised without network access or a key.
+package anthropictest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/anthropic"
+)
+
+// Server is a fake Messages API. Every request to /v1/messages is answered
+// with Reply, streamed word by word when the request asks for a stream.
+// Point anthropic.NewClientWithBaseURL at its URL, with ANTHROPIC_API_KEY
+// set to anything.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	reply      string
+	stopReason string
+	failure    *anthropic.ErrorResponse
+	status     int
+	requests   []anthropic.Request
+}
+
+// NewServer starts a fake server answering "Hello from the fake server."
+// Call Close when done.
+func NewServer() *Server {
+	s := &Server{reply: "Hello from the fake server.", stopReason: "end_turn"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/messages", s.handleMessages)
+	mux.HandleFunc("GET /v1/models", s.handleModels)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetReply sets the text and stop reason of the following replies, e.g.
+// "max_tokens" to fake a reply that was cut off.
+func (s *Server) SetReply(reply, stopReason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reply, s.stopReason = reply, stopReason
+	s.failure = nil
+}
+
+// Fail makes the following requests fail with an error body of the given
+// status, type and message.
+func (s *Server) Fail(status int, errorType, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+	s.failure = &anthropic.ErrorResponse{
+		Type:  "error",
+		Error: anthropic.ErrorDetail{Type: errorType, Message: message},
+	}
+}
+
+// Requests returns the message requests received so far.
+func (s *Server) Requests() []anthropic.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]anthropic.Request{}, s.requests...)
+}
+
+func (s *Server) handleMessages(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r) {
+		return
+	}
+
+	var req anthropic.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	if problem := validate(req); problem != "" {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", problem)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	reply, stopReason, failure, status := s.reply, s.stopReason, s.failure, s.status
+	s.mu.Unlock()
+
+	if failure != nil {
+		writeError(w, status, failure.Error.Type, failure.Error.Message)
+		return
+	}
+
+	inputTokens := 0
+	for _, msg := range req.Messages {
+		for _, block := range msg.Content {
+			inputTokens += len(strings.Fields(block.Text))
+		}
+	}
+	words := strings.SplitAfter(reply, " ")
+
+	if !req.Stream {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(anthropic.Response{
+			ID:         "msg_fake",
+			Model:      req.Model,
+			Content:    []anthropic.ContentBlock{{Type: "text", Text: reply}},
+			StopReason: stopReason,
+			Usage:      anthropic.Usage{InputTokens: inputTokens, OutputTokens: len(words)},
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	flusher, _ := w.(http.Flusher)
+	send := func(eventType string, data any) {
+		body, _ := json.Marshal(data)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, body)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	send("message_start", map[string]any{
+		"type": "message_start",
+		"message": map[string]any{
+			"id": "msg_fake", "type": "message", "role": "assistant", "model": req.Model,
+			"content": []any{}, "usage": map[string]int{"input_tokens": inputTokens, "output_tokens": 1},
+		},
+	})
+	send("content_block_start", map[string]any{
+		"type": "content_block_start", "index": 0,
+		"content_block": map[string]string{"type": "text", "text": ""},
+	})
+	send("ping", map[string]string{"type": "ping"})
+	for _, word := range words {
+		send("content_block_delta", map[string]any{
+			"type": "content_block_delta", "index": 0,
+			"delta": map[string]string{"type": "text_delta", "text": word},
+		})
+	}
+	send("content_block_stop", map[string]any{"type": "content_block_stop", "index": 0})
+	send("message_delta", map[string]any{
+		"type":  "message_delta",
+		"delta": map[string]any{"stop_reason": stopReason, "stop_sequence": nil},
+		"usage": map[string]int{"output_tokens": len(words)},
+	})
+	send("message_stop", map[string]string{"type": "message_stop"})
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": []map[string]string{
+			// Real models are listed by dated snapshot, newest first
+			{"type": "model", "id": "claude-sonnet-4-5-20250929", "display_name": "Claude Sonnet 4.5"},
+			{"type": "model", "id": "claude-sonnet-4-5-20250101", "display_name": "Claude Sonnet 4.5 (old)"},
+			{"type": "model", "id": "claude-fake-1", "display_name": "Claude Fake 1"},
+			{"type": "model", "id": "claude-fake-2", "display_name": "Claude Fake 2"},
+		},
+		"has_more": false,
+	})
+}
+
+// authorized checks the headers the real API insists on.
+func authorized(w http.ResponseWriter, r *http.Request) bool {
+	if r.Header.Get("x-api-key") == "" {
+		writeError(w, http.StatusUnauthorized, "authentication_error", "x-api-key header is required")
+		return false
+	}
+	if r.Header.Get("anthropic-version") == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "anthropic-version header is required")
+		return false
+	}
+	return true
+}
+
+// validate applies the request rules of the real API that the client has
+// to get right.
+func validate(req anthropic.Request) string {
+	switch {
+	case req.Model == "":
+		return "model: field required"
+	case req.MaxTokens <= 0:
+		return "max_tokens: field required"
+	case len(req.Messages) == 0:
+		return "messages: at least one message is required"
+	case req.Messages[0].Role != "user":
+		return "messages: first message must use the \"user\" role"
+	}
+	for i, msg := range req.Messages {
+		if msg.Role != "user" && msg.Role != "assistant" {
+			return fmt.Sprintf("messages.%d.role: unexpected role %q", i, msg.Role)
+		}
+		if i > 0 && req.Messages[i-1].Role == msg.Role {
+			return "messages: roles must alternate between \"user\" and \"assistant\""
+		}
+	}
+	return ""
+}
+
+func writeError(w http.ResponseWriter, status int, errorType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(anthropic.ErrorResponse{
+		Type:  "error",
+		Error: anthropic.ErrorDetail{Type: errorType, Message: message},
+	})
+}
@@ -0,0 +1,327 @@
+// Package anthropic implements a chat provider for Anthropic's Messages
+// API.
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/config"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+)
+
+const (
+	defaultBaseURL = "https://api.anthropic.com"
+	apiVersion     = "2023-06-01"
+	defaultModel   = "claude-sonnet-4-5"
+
+	// The API requires max_tokens, so this is sent when no limit is set
+	defaultMaxTokens = 4096
+	contextWindow    = 200000
+)
+
+// knownModels are offered until a key is set and the real list can be
+// fetched.
+var knownModels = []provider.Model{
+	{ID: "claude-sonnet-4-5", Name: "Claude Sonnet 4.5", ContextWindow: contextWindow, Vision: true},
+	{ID: "claude-haiku-4-5", Name: "Claude Haiku 4.5", ContextWindow: contextWindow, Vision: true},
+	{ID: "claude-opus-4-1", Name: "Claude Opus 4.1", ContextWindow: contextWindow, Vision: true},
+}
+
+// Client talks to the Messages API. The API key is read from
+// ANTHROPIC_API_KEY on every request.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a client for ANTHROPIC_BASE_URL, falling back to the
+// public endpoint.
+func NewClient() *Client {
+	return NewClientWithBaseURL(os.Getenv("ANTHROPIC_BASE_URL"))
+}
+
+// NewClientWithBaseURL returns a client that sends requests to baseURL
+// instead of the public endpoint, e.g. an anthropictest server.
+func NewClientWithBaseURL(baseURL string) *Client {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		baseURL:    baseURL,
+		httpClient: &http.Client{},
+	}
+}
+
+func (c *Client) Name() string {
+	return "anthropic"
+}
+
+func (c *Client) DefaultModel() string {
+	return defaultModel
+}
+
+// Configured reports whether ANTHROPIC_API_KEY is set.
+func (c *Client) Configured() bool {
+	return os.Getenv("ANTHROPIC_API_KEY") != ""
+}
+
+func (c *Client) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Streaming: true, Vision: true}
+}
+
+// ListModels returns the models the key has access to, named by the
+// user's aliases where set. Without a key the best-known models are listed.
+// The API lists dated snapshots such as "claude-sonnet-4-5-20250929"; the
+// latest snapshot of a known model is listed under the model's own ID, the
+// way DefaultModel and saved chats name it.
+func (c *Client) ListModels(ctx context.Context) ([]provider.Model, error) {
+	if !c.Configured() {
+		models := make([]provider.Model, len(knownModels))
+		copy(models, knownModels)
+		return models, nil
+	}
+
+	req, err := c.newRequest(ctx, "GET", "/v1/models?limit=100", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+
+	var parsed ModelsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse model list: %v", err)
+	}
+
+	// A broken aliases file should not hide the models themselves
+	aliases, _ := config.LoadAliases()
+	names := aliases.For(c.Name())
+
+	models := make([]provider.Model, 0, len(parsed.Data))
+	listed := map[string]bool{}
+	for _, m := range parsed.Data {
+		id := m.ID
+		// Snapshots come newest first, so older ones keep their dated ID
+		if known := knownModelID(m.ID); known != "" && !listed[known] {
+			id = known
+		}
+		listed[id] = true
+
+		name := m.DisplayName
+		if alias := names[id]; alias != "" {
+			name = alias
+		} else if alias := names[m.ID]; alias != "" {
+			name = alias
+		}
+		if name == "" {
+			name = id
+		}
+		// Every current Claude model reads images
+		models = append(models, provider.Model{ID: id, Name: name, ContextWindow: contextWindow, Vision: true})
+	}
+	return models, nil
+}
+
+// knownModelID returns the ID of the known model that id is a snapshot of,
+// or "" when it is none of them.
+func knownModelID(id string) string {
+	for _, known := range knownModels {
+		if provider.SameModel(id, known.ID) {
+			return known.ID
+		}
+	}
+	return ""
+}
+
+// Chat sends the conversation and waits for the complete reply.
+func (c *Client) Chat(ctx context.Context, req provider.ChatRequest) (*provider.ChatResponse, error) {
+	resp, err := c.postMessages(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var parsed Response
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+
+	var reply strings.Builder
+	for _, block := range parsed.Content {
+		if block.Type == "text" {
+			reply.WriteString(block.Text)
+		}
+	}
+	if reply.Len() == 0 {
+		return nil, fmt.Errorf("no response recieved")
+	}
+
+	return &provider.ChatResponse{
+		Content:      reply.String(),
+		Usage:        usage(parsed.Usage),
+		FinishReason: finishReason(parsed.StopReason),
+	}, nil
+}
+
+func (c *Client) postMessages(ctx context.Context, req provider.ChatRequest, stream bool) (*http.Response, error) {
+	system, messages := toMessages(req)
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages to send")
+	}
+
+	model := req.Model
+	if model == "" {
+		model = defaultModel
+	}
+
+	payload := Request{
+		Model:         model,
+		System:        system,
+		Messages:      messages,
+		MaxTokens:     defaultMaxTokens,
+		Temperature:   req.Params.Temperature,
+		TopP:          req.Params.TopP,
+		StopSequences: req.Params.Stop,
+		Stream:        stream,
+	}
+	if req.Params.MaxTokens != nil {
+		payload.MaxTokens = *req.Params.MaxTokens
+	}
+	// The API has no temperature above 1
+	if payload.Temperature != nil && *payload.Temperature > 1 {
+		one := 1.0
+		payload.Temperature = &one
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := c.newRequest(ctx, "POST", "/v1/messages", body)
+	if err != nil {
+		return nil, err
+	}
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, statusError(resp)
+	}
+	return resp, nil
+}
+
+// newRequest builds an authenticated API request.
+func (c *Client) newRequest(ctx context.Context, method, path string, body []byte) (*http.Request, error) {
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("ANTHROPIC_API_KEY not set")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", apiKey)
+	req.Header.Set("anthropic-version", apiVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// toMessages maps stored chat messages onto the Messages API. System
+// messages are joined into the top-level system prompt, consecutive turns
+// of the same role are merged so user and assistant alternate, and the
+// conversation starts with a user turn. Local-only, empty and tool entries
+// are left out.
+func toMessages(req provider.ChatRequest) (string, []Message) {
+	var system []string
+	var messages []Message
+
+	for _, msg := range req.Messages {
+		if msg.Content == "" {
+			continue
+		}
+		switch msg.Role {
+		case "system":
+			system = append(system, msg.Content)
+			continue
+		case "user", "assistant":
+		default:
+			continue
+		}
+		if len(messages) == 0 && msg.Role == "assistant" {
+			continue
+		}
+
+		// Images go before the text that asks about them
+		var blocks []ContentBlock
+		if msg.Role == "user" {
+			for _, image := range msg.Images() {
+				blocks = append(blocks, ContentBlock{
+					Type:   "image",
+					Source: &ImageSource{Type: "base64", MediaType: image.MIMEType, Data: image.Base64()},
+				})
+			}
+		}
+		blocks = append(blocks, ContentBlock{Type: "text", Text: msg.Content})
+
+		if last := len(messages) - 1; last >= 0 && messages[last].Role == msg.Role {
+			messages[last].Content = append(messages[last].Content, blocks...)
+			continue
+		}
+		messages = append(messages, Message{Role: msg.Role, Content: blocks})
+	}
+
+	// There is no JSON switch, so JSON mode is asked for in the prompt
+	if req.JSON {
+		system = append(system, "Reply with a single JSON object and nothing else.")
+	}
+	return strings.Join(system, "\n\n"), messages
+}
+
+func usage(u Usage) *storage.Usage {
+	if u.InputTokens == 0 && u.OutputTokens == 0 {
+		return nil
+	}
+	return &storage.Usage{
+		PromptTokens:     u.InputTokens,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      u.InputTokens + u.OutputTokens,
+	}
+}
+
+// finishReason maps a stop reason onto the shared finish reasons.
+func finishReason(stopReason string) string {
+	switch stopReason {
+	case "end_turn", "stop_sequence":
+		return provider.FinishStop
+	case "max_tokens":
+		return provider.FinishLength
+	}
+	return stopReason
+}
@@ -0,0 +1,236 @@
+package anthropic_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/anthropic"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/anthropic/anthropictest"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+)
+
+func newTestClient(t *testing.T) (*anthropic.Client, *anthropictest.Server) {
+	t.Helper()
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	server := anthropictest.NewServer()
+	t.Cleanup(server.Close)
+	return anthropic.NewClientWithBaseURL(server.URL), server
+}
+
+func TestChatMovesSystemMessagesAndMergesTurns(t *testing.T) {
+	client, server := newTestClient(t)
+
+	_, err := client.Chat(context.Background(), provider.ChatRequest{
+		Model: "claude-fake-1",
+		Messages: []storage.ChatMessage{
+			{Role: "assistant", Content: "Greeting before any user turn"},
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: "First question"},
+			{Role: "error", Content: "Error: local only"},
+			{Role: "user", Content: "Second question"},
+			{Role: "assistant", Content: ""},
+			{Role: "assistant", Content: "An answer"},
+			{Role: "system", Content: "Use metric units."},
+			{Role: "assistant", Content: "More of it"},
+			{Role: "user", Content: "Thanks"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("server got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+
+	if want := "Be brief.\n\nUse metric units."; req.System != want {
+		t.Errorf("system = %q, want %q", req.System, want)
+	}
+
+	want := []struct {
+		role  string
+		texts []string
+	}{
+		{"user", []string{"First question", "Second question"}},
+		{"assistant", []string{"An answer", "More of it"}},
+		{"user", []string{"Thanks"}},
+	}
+	if len(req.Messages) != len(want) {
+		t.Fatalf("got %d messages, want %d: %+v", len(req.Messages), len(want), req.Messages)
+	}
+	for i, msg := range req.Messages {
+		if msg.Role != want[i].role {
+			t.Errorf("message %d role = %q, want %q", i, msg.Role, want[i].role)
+		}
+		var texts []string
+		for _, block := range msg.Content {
+			texts = append(texts, block.Text)
+		}
+		if strings.Join(texts, "|") != strings.Join(want[i].texts, "|") {
+			t.Errorf("message %d texts = %q, want %q", i, texts, want[i].texts)
+		}
+	}
+}
+
+func TestChatMapsUsageAndStopReason(t *testing.T) {
+	tests := []struct {
+		stopReason string
+		want       string
+	}{
+		{"end_turn", provider.FinishStop},
+		{"stop_sequence", provider.FinishStop},
+		{"max_tokens", provider.FinishLength},
+		{"refusal", "refusal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.stopReason, func(t *testing.T) {
+			client, server := newTestClient(t)
+			server.SetReply("one two three", tt.stopReason)
+
+			resp, err := client.Chat(context.Background(), provider.ChatRequest{
+				Messages: []storage.ChatMessage{{Role: "user", Content: "count to three"}},
+			})
+			if err != nil {
+				t.Fatalf("Chat: %v", err)
+			}
+			if resp.Content != "one two three" {
+				t.Errorf("content = %q", resp.Content)
+			}
+			if resp.FinishReason != tt.want {
+				t.Errorf("finish reason = %q, want %q", resp.FinishReason, tt.want)
+			}
+			if resp.Usage == nil {
+				t.Fatal("usage missing")
+			}
+			if resp.Usage.PromptTokens != 3 || resp.Usage.CompletionTokens != 3 || resp.Usage.TotalTokens != 6 {
+				t.Errorf("usage = %+v, want 3 prompt and 3 completion tokens", *resp.Usage)
+			}
+		})
+	}
+}
+
+func TestStreamDeliversTextDeltas(t *testing.T) {
+	client, server := newTestClient(t)
+	server.SetReply("Streamed word by word.", "max_tokens")
+
+	var deltas []string
+	resp, err := client.Stream(context.Background(), provider.ChatRequest{
+		Messages: []storage.ChatMessage{{Role: "user", Content: "Say something"}},
+	}, func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+
+	if len(deltas) != 4 {
+		t.Errorf("got %d deltas, want one per word: %q", len(deltas), deltas)
+	}
+	if got := strings.Join(deltas, ""); got != "Streamed word by word." {
+		t.Errorf("deltas join to %q", got)
+	}
+	if resp.Content != "Streamed word by word." {
+		t.Errorf("content = %q", resp.Content)
+	}
+	if resp.FinishReason != provider.FinishLength {
+		t.Errorf("finish reason = %q, want %q", resp.FinishReason, provider.FinishLength)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 2 || resp.Usage.CompletionTokens != 4 {
+		t.Errorf("usage = %+v, want 2 prompt and 4 completion tokens", resp.Usage)
+	}
+	if requests := server.Requests(); len(requests) != 1 || !requests[0].Stream {
+		t.Errorf("request did not ask for a stream: %+v", requests)
+	}
+}
+
+func TestErrorsCarryHints(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		errorType string
+		message   string
+		hint      string
+	}{
+		{"bad key", http.StatusUnauthorized, "authentication_error", "invalid x-api-key", "ANTHROPIC_API_KEY"},
+		{"unknown model", http.StatusNotFound, "not_found_error", "model: claude-nope", "Pick another one"},
+		{"too long", http.StatusBadRequest, "invalid_request_error", "prompt is too long: 210000 tokens > 200000 maximum", "Start a new chat"},
+		{"rate limited", http.StatusTooManyRequests, "rate_limit_error", "slow down", "rate limited"},
+		{"overloaded", 529, "overloaded_error", "Overloaded", "trouble right now"},
+		{"other", http.StatusBadRequest, "invalid_request_error", "temperature: out of range", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestClient(t)
+			server.Fail(tt.status, tt.errorType, tt.message)
+
+			_, err := client.Chat(context.Background(), provider.ChatRequest{
+				Messages: []storage.ChatMessage{{Role: "user", Content: "hi"}},
+			})
+			var apiErr *anthropic.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want an *anthropic.APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Type != tt.errorType || apiErr.Message != tt.message {
+				t.Errorf("error = %+v", *apiErr)
+			}
+
+			hint := apiErr.Hint()
+			if tt.hint == "" && hint != "" {
+				t.Errorf("hint = %q, want none", hint)
+			}
+			if !strings.Contains(hint, tt.hint) {
+				t.Errorf("hint = %q, want it to mention %q", hint, tt.hint)
+			}
+		})
+	}
+}
+
+func TestMissingKey(t *testing.T) {
+	client, server := newTestClient(t)
+	t.Setenv("ANTHROPIC_API_KEY", "")
+
+	_, err := client.Chat(context.Background(), provider.ChatRequest{
+		Messages: []storage.ChatMessage{{Role: "user", Content: "hi"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "ANTHROPIC_API_KEY") {
+		t.Errorf("error = %v, want one naming ANTHROPIC_API_KEY", err)
+	}
+	if requests := server.Requests(); len(requests) != 0 {
+		t.Errorf("server got %d requests without a key", len(requests))
+	}
+}
+
+func TestListModelsNamesKnownSnapshotsByTheirModel(t *testing.T) {
+	client, _ := newTestClient(t)
+	t.Setenv("TUI_GPT_CONFIG_DIR", t.TempDir())
+
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	var ids []string
+	for _, m := range models {
+		ids = append(ids, m.ID)
+	}
+	want := []string{client.DefaultModel(), "claude-sonnet-4-5-20250101", "claude-fake-1", "claude-fake-2"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("listed %q, want %q", ids, want)
+	}
+
+	// Without a key the known models are listed, the default among them
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	models, err = client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels without a key: %v", err)
+	}
+	if !slices.ContainsFunc(models, func(m provider.Model) bool { return m.ID == client.DefaultModel() }) {
+		t.Errorf("known models %+v leave out the default %q", models, client.DefaultModel())
+	}
+}
@@ -0,0 +1,63 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// APIError is an error response from the Messages API, which uses the
+// {"type": "error", "error": {"type", "message"}} body.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	detail := fmt.Sprintf("HTTP %d", e.StatusCode)
+	if e.Type != "" {
+		detail += ", " + e.Type
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, detail)
+}
+
+// Hint suggests how the user can fix the error.
+func (e *APIError) Hint() string {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.Type == "authentication_error":
+		return "The API key was rejected. Check ANTHROPIC_API_KEY in your .env file."
+	case e.Type == "not_found_error":
+		return "This model is not served. Pick another one with Ctrl+-."
+	case strings.Contains(e.Message, "prompt is too long"):
+		return "The conversation is too long for this model. Start a new chat with Ctrl+N."
+	case e.StatusCode == http.StatusTooManyRequests || e.Type == "rate_limit_error":
+		return "You are being rate limited. Wait a moment before retrying."
+	case e.Type == "overloaded_error" || e.StatusCode >= 500:
+		return "Anthropic is having trouble right now. Try again shortly."
+	}
+	return ""
+}
+
+// statusError turns a failed response into an *APIError, falling back to
+// the raw body when it is not the usual error JSON.
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	var parsed ErrorResponse
+	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Error.Message != "" {
+		apiErr.Type = parsed.Error.Type
+		apiErr.Message = parsed.Error.Message
+		return apiErr
+	}
+
+	apiErr.Message = strings.TrimSpace(string(body))
+	if apiErr.Message == "" {
+		apiErr.Message = resp.Status
+	}
+	return apiErr
+}
@@ -0,0 +1,99 @@
+package anthropic
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+)
+
+// Stream sends the conversation with streaming enabled and calls onDelta
+// for the text of every content_block_delta event. If ctx is cancelled the
+// text received so far is returned along with the context's error.
+func (c *Client) Stream(ctx context.Context, req provider.ChatRequest, onDelta func(string)) (*provider.ChatResponse, error) {
+	resp, err := c.postMessages(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	reply, err := readStream(resp.Body, onDelta)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+	return reply, err
+}
+
+// readStream parses the Messages API event stream. The returned response
+// is never nil and holds whatever arrived before any error.
+func readStream(r io.Reader, onDelta func(string)) (*provider.ChatResponse, error) {
+	var reply strings.Builder
+	var tokens Usage
+	result := &provider.ChatResponse{}
+	partial := func() *provider.ChatResponse {
+		result.Content = reply.String()
+		result.Usage = usage(tokens)
+		return result
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			// Blank separators and the "event:" lines, which repeat the type
+			continue
+		}
+
+		var event StreamEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &event); err != nil {
+			return partial(), fmt.Errorf("failed to parse stream event: %v", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				tokens.InputTokens = event.Message.Usage.InputTokens
+				tokens.OutputTokens = event.Message.Usage.OutputTokens
+			}
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+				continue
+			}
+			reply.WriteString(event.Delta.Text)
+			if onDelta != nil {
+				onDelta(event.Delta.Text)
+			}
+		case "message_delta":
+			if event.Delta.StopReason != "" {
+				result.FinishReason = finishReason(event.Delta.StopReason)
+			}
+			if event.Usage != nil {
+				tokens.OutputTokens = event.Usage.OutputTokens
+			}
+		case "message_stop":
+			if reply.Len() == 0 {
+				return partial(), fmt.Errorf("no response recieved")
+			}
+			return partial(), nil
+		case "error":
+			apiErr := &APIError{StatusCode: http.StatusOK, Message: "stream failed"}
+			if event.Error != nil {
+				apiErr.Type = event.Error.Type
+				apiErr.Message = event.Error.Message
+			}
+			return partial(), apiErr
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return partial(), err
+	}
+	return partial(), fmt.Errorf("stream ended before message_stop")
+}
@@ -0,0 +1,87 @@
+package anthropic
+
+// Request is the body of POST /v1/messages.
+type Request struct {
+	Model         string    `json:"model"`
+	System        string    `json:"system,omitempty"`
+	Messages      []Message `json:"messages"`
+	MaxTokens     int       `json:"max_tokens"`
+	Temperature   *float64  `json:"temperature,omitempty"`
+	TopP          *float64  `json:"top_p,omitempty"`
+	StopSequences []string  `json:"stop_sequences,omitempty"`
+	Stream        bool      `json:"stream,omitempty"`
+}
+
+// Message is one turn. Turns alternate between "user" and "assistant",
+// starting with "user".
+type Message struct {
+	Role    string         `json:"role"`
+	Content []ContentBlock `json:"content"`
+}
+
+// ContentBlock is a piece of a message: a text block, or an image block
+// with its Source.
+type ContentBlock struct {
+	Type   string       `json:"type"`
+	Text   string       `json:"text,omitempty"`
+	Source *ImageSource `json:"source,omitempty"`
+}
+
+// ImageSource holds an inline image, of type "base64".
+type ImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+// Response is the body of a complete, non-streamed reply.
+type Response struct {
+	ID         string         `json:"id"`
+	Model      string         `json:"model"`
+	Content    []ContentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      Usage          `json:"usage"`
+}
+
+// Usage is the token accounting of a reply. In a stream the input tokens
+// arrive with message_start and the output tokens with message_delta.
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// StreamEvent is the data of one server-sent event. Which fields are set
+// depends on Type: message_start, content_block_start, content_block_delta,
+// content_block_stop, message_delta, message_stop, ping or error.
+type StreamEvent struct {
+	Type    string    `json:"type"`
+	Message *Response `json:"message,omitempty"`
+	Index   int       `json:"index"`
+	Delta   struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *Usage       `json:"usage,omitempty"`
+	Error *ErrorDetail `json:"error,omitempty"`
+}
+
+// ModelsResponse is the body of GET /v1/models.
+type ModelsResponse struct {
+	Data []struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+	} `json:"data"`
+}
+
+// ErrorResponse is the body of a failed request, and of an error event in
+// a stream.
+type ErrorResponse struct {
+	Type  string      `json:"type"`
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
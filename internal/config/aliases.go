@@ -0,0 +1,26 @@
+package config
+
+const aliasesFile = "aliases.json"
+
+// Aliases holds user-defined display names for models, keyed by provider
+// name and then model ID, e.g.
+//
+//	{"groq": {"llama-3.3-70b-versatile": "Llama 3.3 70B"}}
+type Aliases map[string]map[string]string
+
+// LoadAliases reads aliases.json from the configuration directory.
+func LoadAliases() (Aliases, error) {
+	aliases := Aliases{}
+	if err := Load(aliasesFile, &aliases); err != nil {
+		return Aliases{}, err
+	}
+	return aliases, nil
+}
+
+// For returns the aliases of a single provider. The result is never nil.
+func (a Aliases) For(providerName string) map[string]string {
+	if names, exists := a[providerName]; exists && names != nil {
+		return names
+	}
+	return map[string]string{}
+}
@@ -0,0 +1,105 @@
+// Package config locates the user's configuration and cache directories and
+// reads and writes the JSON files kept in them.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const appDirName = "tui-gpt"
+
+// Dir returns the configuration directory, creating it if needed.
+// TUI_GPT_CONFIG_DIR overrides the platform default.
+func Dir() (string, error) {
+	if dir := os.Getenv("TUI_GPT_CONFIG_DIR"); dir != "" {
+		return dir, os.MkdirAll(dir, 0755)
+	}
+
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %v", err)
+	}
+	dir := filepath.Join(base, appDirName)
+	return dir, os.MkdirAll(dir, 0755)
+}
+
+// CacheDir returns the cache directory, creating it if needed.
+// TUI_GPT_CACHE_DIR overrides the platform default.
+func CacheDir() (string, error) {
+	if dir := os.Getenv("TUI_GPT_CACHE_DIR"); dir != "" {
+		return dir, os.MkdirAll(dir, 0755)
+	}
+
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %v", err)
+	}
+	dir := filepath.Join(base, appDirName)
+	return dir, os.MkdirAll(dir, 0755)
+}
+
+// Load reads the named file from the configuration directory into v. A
+// missing file leaves v untouched and is not an error.
+func Load(name string, v any) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	return readJSON(filepath.Join(dir, name), v)
+}
+
+// Save writes v to the named file in the configuration directory.
+func Save(name string, v any) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(dir, name), v)
+}
+
+// LoadCache reads the named file from the cache directory into v. A
+// missing file leaves v untouched and is not an error.
+func LoadCache(name string, v any) error {
+	dir, err := CacheDir()
+	if err != nil {
+		return err
+	}
+	return readJSON(filepath.Join(dir, name), v)
+}
+
+// SaveCache writes v to the named file in the cache directory.
+func SaveCache(name string, v any) error {
+	dir, err := CacheDir()
+	if err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(dir, name), v)
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", filepath.Base(path), err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %v", filepath.Base(path), err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,32 @@
+package config
+
+import "github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+
+const personasFile = "personas.json"
+
+// Persona is a reusable system prompt together with the model and sampling
+// settings it works best with. Provider, Model and Params are optional.
+type Persona struct {
+	Name         string                    `json:"name"`
+	SystemPrompt string                    `json:"system_prompt"`
+	Provider     string                    `json:"provider,omitempty"`
+	Model        string                    `json:"model,omitempty"`
+	Params       *storage.GenerationParams `json:"params,omitempty"`
+}
+
+// LoadPersonas reads the persona library from personas.json in the
+// configuration directory. Entries without a name are skipped.
+func LoadPersonas() ([]Persona, error) {
+	var personas []Persona
+	if err := Load(personasFile, &personas); err != nil {
+		return nil, err
+	}
+
+	valid := personas[:0]
+	for _, persona := range personas {
+		if persona.Name != "" {
+			valid = append(valid, persona)
+		}
+	}
+	return valid, nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const profilesFile = "profiles.json"
+
+// Profile is a named OpenAI-compatible endpoint, such as a vLLM, LM Studio
+// or llama.cpp server, OpenRouter or an internal gateway. Only Name and
+// BaseURL are required.
+type Profile struct {
+	Name string `json:"name"`
+	// BaseURL is the API root that /chat/completions and /models are
+	// appended to, e.g. "http://localhost:8000/v1".
+	BaseURL string `json:"base_url"`
+	// APIKeyEnv names the environment variable holding the API key.
+	// APIKeyFile is read instead when that variable is unset; a relative
+	// path is taken from the config directory. Profiles with neither send
+	// no Authorization header.
+	APIKeyEnv  string `json:"api_key_env,omitempty"`
+	APIKeyFile string `json:"api_key_file,omitempty"`
+	// Headers are sent with every request, e.g. OpenRouter's HTTP-Referer.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Models, when set, limits the models offered to these IDs.
+	Models       []string `json:"models,omitempty"`
+	DefaultModel string   `json:"default_model,omitempty"`
+	// TranscriptionModel is the speech model used for audio input,
+	// "whisper-1" unless set.
+	TranscriptionModel string `json:"transcription_model,omitempty"`
+}
+
+// LoadProfiles reads the endpoint profiles from profiles.json in the
+// configuration directory. Entries without a name or base URL are skipped.
+func LoadProfiles() ([]Profile, error) {
+	var profiles []Profile
+	if err := Load(profilesFile, &profiles); err != nil {
+		return nil, err
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	valid := profiles[:0]
+	for _, profile := range profiles {
+		profile.Name = strings.TrimSpace(profile.Name)
+		profile.BaseURL = strings.TrimRight(strings.TrimSpace(profile.BaseURL), "/")
+		if profile.Name == "" || profile.BaseURL == "" {
+			continue
+		}
+		if seen[profile.Name] {
+			return nil, fmt.Errorf("%s: profile %q is defined twice", profilesFile, profile.Name)
+		}
+		seen[profile.Name] = true
+
+		if profile.APIKeyFile != "" && !filepath.IsAbs(profile.APIKeyFile) {
+			profile.APIKeyFile = filepath.Join(dir, profile.APIKeyFile)
+		}
+		valid = append(valid, profile)
+	}
+	return valid, nil
+}
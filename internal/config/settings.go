@@ -0,0 +1,39 @@
+package config
+
+import "github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+
+const settingsFile = "settings.json"
+
+// Settings are the user's global preferences.
+type Settings struct {
+	// Default sampling parameters, overridden by those of a chat
+	Params storage.GenerationParams `json:"params"`
+	// How to trim chats that outgrow the context window, unless a chat
+	// says otherwise
+	ContextStrategy string `json:"context_strategy,omitempty"`
+	// AutoTitle asks a model for each chat's title and summary after the
+	// first exchange, using TitleProvider and TitleModel when set. Unset,
+	// the chat's provider and its cheapest listed model are used.
+	AutoTitle     bool   `json:"auto_title,omitempty"`
+	TitleProvider string `json:"title_provider,omitempty"`
+	TitleModel    string `json:"title_model,omitempty"`
+	// AutoContinue asks for the rest of replies cut off by max_tokens
+	AutoContinue bool `json:"auto_continue,omitempty"`
+	// TranscriptionLanguage is the ISO-639-1 code of the language spoken
+	// in recordings. Whisper detects it when unset.
+	TranscriptionLanguage string `json:"transcription_language,omitempty"`
+}
+
+// LoadSettings reads settings.json from the configuration directory.
+func LoadSettings() (Settings, error) {
+	var settings Settings
+	if err := Load(settingsFile, &settings); err != nil {
+		return Settings{}, err
+	}
+	return settings, nil
+}
+
+// SaveSettings writes settings.json to the configuration directory.
+func SaveSettings(settings Settings) error {
+	return Save(settingsFile, settings)
+}
@@ -0,0 +1,129 @@
+// Package continuation asks a model to carry on with a reply that was cut
+// off by max_tokens, and stitches the pieces back into one reply.
+package continuation
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+)
+
+// MaxRounds caps how many follow-up requests a single reply may take.
+const MaxRounds = 3
+
+const (
+	prompt = "Your reply was cut off. Continue exactly where it stopped, " +
+		"without repeating anything and without any introduction. " +
+		"If it stopped inside a code block, continue the code without opening a new block."
+
+	// Repeated text shorter than minOverlap is taken as a coincidence
+	minOverlap = 8
+	maxOverlap = 500
+)
+
+// Generate sends req, streaming when the provider can, and while the reply
+// is cut off by max_tokens asks for the rest, up to MaxRounds more times.
+// onDelta receives each piece as it arrives. The returned reply is the
+// stitched whole, with usage totalled over every request, together with the
+// number of follow-ups it took. On failure the reply so far is returned
+// with the error.
+func Generate(ctx context.Context, p provider.Provider, req provider.ChatRequest, onDelta func(string)) (*provider.ChatResponse, int, error) {
+	resp, err := send(ctx, p, req, onDelta)
+	if err != nil || resp == nil {
+		return resp, 0, err
+	}
+
+	total := *resp
+	rounds := 0
+	for total.FinishReason == provider.FinishLength && rounds < MaxRounds {
+		rounds++
+		follow := req
+		follow.Messages = append(req.Messages[:len(req.Messages):len(req.Messages)],
+			storage.ChatMessage{Role: "assistant", Content: total.Content},
+			storage.ChatMessage{Role: "user", Content: prompt},
+		)
+
+		next, err := send(ctx, p, follow, onDelta)
+		if next != nil {
+			total.Content = Stitch(total.Content, next.Content)
+			total.FinishReason = next.FinishReason
+			if next.Usage != nil {
+				usage := storage.Usage{}
+				if total.Usage != nil {
+					usage = *total.Usage
+				}
+				usage.Add(*next.Usage)
+				total.Usage = &usage
+			}
+		}
+		if err != nil {
+			return &total, rounds, err
+		}
+	}
+	return &total, rounds, nil
+}
+
+func send(ctx context.Context, p provider.Provider, req provider.ChatRequest, onDelta func(string)) (*provider.ChatResponse, error) {
+	if p.Capabilities().Streaming {
+		return p.Stream(ctx, req, onDelta)
+	}
+	return p.Chat(ctx, req)
+}
+
+// Stitch joins a reply that was cut off and its continuation. A code block
+// left open at the cut carries on instead of being reopened, so a fence the
+// continuation reopens it with is dropped, and so is any text the
+// continuation repeats from the end of the reply. A fence that closes the
+// block is kept.
+func Stitch(reply, continuation string) string {
+	if openFence(reply) {
+		first, rest, _ := strings.Cut(strings.TrimLeft(continuation, " \t\r\n"), "\n")
+		if reopensFence(first, rest) {
+			continuation = rest
+		}
+	}
+	return reply + continuation[overlap(reply, continuation):]
+}
+
+// reopensFence reports whether first, the first line of the continuation
+// of an open code block, opens the block again rather than closing it: the
+// fence names a language, or the code after it is closed by a bare fence.
+func reopensFence(first, rest string) bool {
+	first = strings.TrimSpace(first)
+	if !strings.HasPrefix(first, "```") {
+		return false
+	}
+	if strings.Trim(first, "`") != "" {
+		return true
+	}
+	for _, line := range strings.Split(rest, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "```") {
+			return strings.Trim(line, "`") == ""
+		}
+	}
+	return false
+}
+
+// openFence reports whether text ends inside a fenced code block.
+func openFence(text string) bool {
+	open := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			open = !open
+		}
+	}
+	return open
+}
+
+// overlap returns the length of the longest start of continuation that
+// repeats the end of reply, or zero when it is too short to be a repeat.
+func overlap(reply, continuation string) int {
+	for n := min(len(reply), len(continuation), maxOverlap); n >= minOverlap; n-- {
+		if strings.HasSuffix(reply, continuation[:n]) {
+			return n
+		}
+	}
+	return 0
+}
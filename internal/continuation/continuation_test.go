@@ -0,0 +1,84 @@
+package continuation
+
+import "testing"
+
+func TestStitch(t *testing.T) {
+	tests := []struct {
+		name         string
+		reply        string
+		continuation string
+		want         string
+	}{
+		{
+			name:         "prose",
+			reply:        "The quick brown fox",
+			continuation: " jumps over the lazy dog.",
+			want:         "The quick brown fox jumps over the lazy dog.",
+		},
+		{
+			name:         "overlapping text",
+			reply:        "The quick brown fox jumps",
+			continuation: "brown fox jumps over the lazy dog.",
+			want:         "The quick brown fox jumps over the lazy dog.",
+		},
+		{
+			name:         "open fence and closing fence",
+			reply:        "Here it is:\n```go\nfmt.Println(\"hi\")\n",
+			continuation: "```\n\nThat prints hi.",
+			want:         "Here it is:\n```go\nfmt.Println(\"hi\")\n```\n\nThat prints hi.",
+		},
+		{
+			name:         "open fence reopened with a language",
+			reply:        "Here it is:\n```go\nx := 1\n",
+			continuation: "```go\ny := 2\n```",
+			want:         "Here it is:\n```go\nx := 1\ny := 2\n```",
+		},
+		{
+			name:         "open fence reopened bare",
+			reply:        "Here it is:\n```go\nx := 1\n",
+			continuation: "\n```\ny := 2\n```\n\nDone.",
+			want:         "Here it is:\n```go\nx := 1\ny := 2\n```\n\nDone.",
+		},
+		{
+			name:         "closing fence followed by another block",
+			reply:        "Here it is:\n```go\nx := 1\n",
+			continuation: "```\n\nAnd in Python:\n```python\nx = 1\n```",
+			want:         "Here it is:\n```go\nx := 1\n```\n\nAnd in Python:\n```python\nx = 1\n```",
+		},
+		{
+			name:         "fence in a closed block",
+			reply:        "```go\nx := 1\n```\n\nNext:",
+			continuation: "```go\ny := 2\n```",
+			want:         "```go\nx := 1\n```\n\nNext:```go\ny := 2\n```",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Stitch(tt.reply, tt.continuation); got != tt.want {
+				t.Errorf("Stitch = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	tests := []struct {
+		name         string
+		reply        string
+		continuation string
+		want         int
+	}{
+		{"no overlap", "The quick brown fox", " jumps over the lazy dog.", 0},
+		{"repeated end", "The quick brown fox jumps", "brown fox jumps over", len("brown fox jumps")},
+		{"longest repeat wins", "abcabcabcabc", "abcabcabcabc and more", len("abcabcabcabc")},
+		{"too short to be a repeat", "The quick brown fox", "fox jumps", 0},
+		{"empty continuation", "The quick brown fox", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overlap(tt.reply, tt.continuation); got != tt.want {
+				t.Errorf("overlap(%q, %q) = %d, want %d", tt.reply, tt.continuation, got, tt.want)
+			}
+		})
+	}
+}
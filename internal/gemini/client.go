@@ -0,0 +1,272 @@
+// Package gemini implements a chat provider for Google's Gemini models on
+// top of the genai SDK.
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+	"google.golang.org/genai"
+)
+
+const defaultModel = "gemini-2.0-flash"
+
+var availableModels = []provider.Model{
+	{ID: "gemini-2.0-flash", Name: "Gemini 2.0 Flash", ContextWindow: 1048576, Vision: true},
+	{ID: "gemini-2.0-flash-lite", Name: "Gemini 2.0 Flash Lite", ContextWindow: 1048576, Vision: true},
+	{ID: "gemini-1.5-pro", Name: "Gemini 1.5 Pro", ContextWindow: 2097152, Vision: true},
+	{ID: "gemini-1.5-flash", Name: "Gemini 1.5 Flash", ContextWindow: 1048576, Vision: true},
+}
+
+// Client talks to the Gemini API. The API key is read from GEMINI_API_KEY,
+// or GOOGLE_API_KEY, on every request.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a client for GEMINI_BASE_URL, falling back to the
+// public endpoint.
+func NewClient() *Client {
+	return NewClientWithBaseURL(os.Getenv("GEMINI_BASE_URL"))
+}
+
+// NewClientWithBaseURL returns a client that sends requests to baseURL
+// instead of the public endpoint, e.g. a geminitest server.
+func NewClientWithBaseURL(baseURL string) *Client {
+	return &Client{
+		baseURL:    baseURL,
+		httpClient: &http.Client{},
+	}
+}
+
+func (c *Client) Name() string {
+	return "gemini"
+}
+
+func (c *Client) DefaultModel() string {
+	return defaultModel
+}
+
+// Configured reports whether a Gemini API key is set.
+func (c *Client) Configured() bool {
+	return apiKey() != ""
+}
+
+func (c *Client) ListModels(ctx context.Context) ([]provider.Model, error) {
+	models := make([]provider.Model, len(availableModels))
+	copy(models, availableModels)
+	return models, nil
+}
+
+func (c *Client) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Streaming: true, Vision: true}
+}
+
+// Chat sends the conversation and waits for the complete reply.
+func (c *Client) Chat(ctx context.Context, req provider.ChatRequest) (*provider.ChatResponse, error) {
+	client, err := c.newGenaiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, config := toContents(req)
+	if len(contents) == 0 {
+		return nil, fmt.Errorf("no messages to send")
+	}
+
+	resp, err := client.Models.GenerateContent(ctx, modelOrDefault(req.Model), contents, config)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := responseText(resp)
+	if reply == "" {
+		return nil, fmt.Errorf("no response recieved")
+	}
+	return &provider.ChatResponse{Content: reply, Usage: usage(resp), FinishReason: finishReason(resp)}, nil
+}
+
+// Stream sends the conversation and calls onDelta for each chunk of text
+// as it arrives.
+func (c *Client) Stream(ctx context.Context, req provider.ChatRequest, onDelta func(string)) (*provider.ChatResponse, error) {
+	client, err := c.newGenaiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, config := toContents(req)
+	if len(contents) == 0 {
+		return nil, fmt.Errorf("no messages to send")
+	}
+
+	var reply strings.Builder
+	result := &provider.ChatResponse{}
+	for resp, err := range client.Models.GenerateContentStream(ctx, modelOrDefault(req.Model), contents, config) {
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				err = ctxErr
+			}
+			result.Content = reply.String()
+			return result, err
+		}
+		if u := usage(resp); u != nil {
+			result.Usage = u
+		}
+		if reason := finishReason(resp); reason != "" {
+			result.FinishReason = reason
+		}
+
+		delta := responseText(resp)
+		if delta == "" {
+			continue
+		}
+		reply.WriteString(delta)
+		if onDelta != nil {
+			onDelta(delta)
+		}
+	}
+
+	result.Content = reply.String()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return result, ctxErr
+	}
+	if reply.Len() == 0 {
+		return nil, fmt.Errorf("no response recieved")
+	}
+	return result, nil
+}
+
+func (c *Client) newGenaiClient(ctx context.Context) (*genai.Client, error) {
+	key := apiKey()
+	if key == "" {
+		return nil, fmt.Errorf("GEMINI_API_KEY not set")
+	}
+
+	return genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:      key,
+		Backend:     genai.BackendGeminiAPI,
+		HTTPClient:  c.httpClient,
+		HTTPOptions: genai.HTTPOptions{BaseURL: c.baseURL},
+	})
+}
+
+func apiKey() string {
+	if key := os.Getenv("GEMINI_API_KEY"); key != "" {
+		return key
+	}
+	return os.Getenv("GOOGLE_API_KEY")
+}
+
+// toContents maps stored chat messages onto Gemini contents. System
+// messages become the system instruction, assistant turns use the "model"
+// role, images are sent inline after the text of user turns, and
+// local-only or empty entries are left out. Sampling parameters
+// and JSON mode go into the returned config.
+func toContents(req provider.ChatRequest) ([]*genai.Content, *genai.GenerateContentConfig) {
+	var contents []*genai.Content
+	var system []*genai.Part
+
+	for _, msg := range req.Messages {
+		if msg.Content == "" {
+			continue
+		}
+		switch msg.Role {
+		case "system":
+			system = append(system, genai.NewPartFromText(msg.Content))
+		case "user":
+			parts := []*genai.Part{genai.NewPartFromText(msg.Content)}
+			for _, image := range msg.Images() {
+				parts = append(parts, genai.NewPartFromBytes(image.Data, image.MIMEType))
+			}
+			contents = append(contents, genai.NewContentFromParts(parts, genai.RoleUser))
+		case "assistant":
+			contents = append(contents, genai.NewContentFromText(msg.Content, genai.RoleModel))
+		}
+	}
+
+	config := &genai.GenerateContentConfig{}
+	if len(system) > 0 {
+		config.SystemInstruction = &genai.Content{Parts: system}
+	}
+	if req.JSON {
+		config.ResponseMIMEType = "application/json"
+	}
+
+	params := req.Params
+	if params.Temperature != nil {
+		config.Temperature = genai.Ptr(float32(*params.Temperature))
+	}
+	if params.TopP != nil {
+		config.TopP = genai.Ptr(float32(*params.TopP))
+	}
+	if params.MaxTokens != nil {
+		config.MaxOutputTokens = int32(*params.MaxTokens)
+	}
+	if params.Seed != nil {
+		config.Seed = genai.Ptr(int32(*params.Seed))
+	}
+	config.StopSequences = params.Stop
+	return contents, config
+}
+
+// responseText joins the text parts of the first candidate, skipping
+// thought summaries.
+func responseText(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return ""
+	}
+
+	var text strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if part.Thought {
+			continue
+		}
+		text.WriteString(part.Text)
+	}
+	return text.String()
+}
+
+// finishReason maps Gemini's finish reason onto the shared ones, passing
+// the others through in lower case.
+func finishReason(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 {
+		return ""
+	}
+	switch reason := resp.Candidates[0].FinishReason; reason {
+	case "", genai.FinishReasonUnspecified:
+		return ""
+	case genai.FinishReasonStop:
+		return provider.FinishStop
+	case genai.FinishReasonMaxTokens:
+		return provider.FinishLength
+	default:
+		return strings.ToLower(string(reason))
+	}
+}
+
+// usage converts Gemini's usage metadata. Streamed chunks repeat the
+// running totals, so the last one wins.
+func usage(resp *genai.GenerateContentResponse) *storage.Usage {
+	if resp == nil || resp.UsageMetadata == nil {
+		return nil
+	}
+	meta := resp.UsageMetadata
+	return &storage.Usage{
+		PromptTokens:     int(meta.PromptTokenCount),
+		CompletionTokens: int(meta.CandidatesTokenCount),
+		TotalTokens:      int(meta.TotalTokenCount),
+	}
+}
+
+func modelOrDefault(model string) string {
+	if model == "" {
+		return defaultModel
+	}
+	return model
+}
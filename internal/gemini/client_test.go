@@ -0,0 +1,244 @@
+package gemini_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/gemini"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/gemini/geminitest"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+	"google.golang.org/genai"
+)
+
+// turns flattens contents into "role: text" lines.
+func turns(contents []*genai.Content) []string {
+	var lines []string
+	for _, content := range contents {
+		for _, part := range content.Parts {
+			lines = append(lines, content.Role+": "+part.Text)
+		}
+	}
+	return lines
+}
+
+func TestContentsAndSystemInstruction(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []storage.ChatMessage
+		system   []string
+		contents []string
+	}{
+		{
+			name: "assistant turns use the model role",
+			messages: []storage.ChatMessage{
+				{Role: "user", Content: "Hi"},
+				{Role: "assistant", Content: "Hello!"},
+				{Role: "user", Content: "How are you?"},
+			},
+			contents: []string{"user: Hi", "model: Hello!", "user: How are you?"},
+		},
+		{
+			name: "system messages become the instruction",
+			messages: []storage.ChatMessage{
+				{Role: "system", Content: "Be brief."},
+				{Role: "user", Content: "Hi"},
+				{Role: "system", Content: "Answer in French."},
+			},
+			system:   []string{"Be brief.", "Answer in French."},
+			contents: []string{"user: Hi"},
+		},
+		{
+			name: "local and empty entries are left out",
+			messages: []storage.ChatMessage{
+				{Role: "user", Content: "Hi"},
+				{Role: "assistant", Content: ""},
+				{Role: "error", Content: "Error: quota exceeded"},
+				{Role: "user", Content: "Again"},
+			},
+			contents: []string{"user: Hi", "user: Again"},
+		},
+	}
+
+	server := geminitest.NewServer(t)
+	client := gemini.NewClientWithBaseURL(server.URL)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.Chat(context.Background(), provider.ChatRequest{Messages: tt.messages}); err != nil {
+				t.Fatalf("Chat: %v", err)
+			}
+
+			req := server.LastRequest()
+			if got := turns(req.Contents); !reflect.DeepEqual(got, tt.contents) {
+				t.Errorf("contents = %q, want %q", got, tt.contents)
+			}
+
+			var system []string
+			if req.SystemInstruction != nil {
+				for _, part := range req.SystemInstruction.Parts {
+					system = append(system, part.Text)
+				}
+			}
+			if !reflect.DeepEqual(system, tt.system) {
+				t.Errorf("system instruction = %q, want %q", system, tt.system)
+			}
+		})
+	}
+}
+
+func TestModel(t *testing.T) {
+	server := geminitest.NewServer(t)
+	client := gemini.NewClientWithBaseURL(server.URL)
+	hi := []storage.ChatMessage{{Role: "user", Content: "Hi"}}
+
+	if _, err := client.Chat(context.Background(), provider.ChatRequest{Messages: hi}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if got := server.LastRequest().Model; got != client.DefaultModel() {
+		t.Errorf("model = %q, want the default %q", got, client.DefaultModel())
+	}
+
+	if _, err := client.Chat(context.Background(), provider.ChatRequest{Model: "gemini-1.5-pro", Messages: hi}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if got := server.LastRequest().Model; got != "gemini-1.5-pro" {
+		t.Errorf("model = %q, want gemini-1.5-pro", got)
+	}
+}
+
+func TestThoughtSummariesAreLeftOut(t *testing.T) {
+	server := geminitest.NewServer(t)
+	server.Respond(
+		geminitest.Chunk{Text: "The user greets me.", Thought: true},
+		geminitest.Chunk{Text: "Hello"},
+		geminitest.Chunk{Text: " there!", FinishReason: genai.FinishReasonStop},
+	)
+	client := gemini.NewClientWithBaseURL(server.URL)
+	req := provider.ChatRequest{Messages: []storage.ChatMessage{{Role: "user", Content: "Hi"}}}
+
+	resp, err := client.Chat(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if resp.Content != "Hello there!" {
+		t.Errorf("Chat content = %q", resp.Content)
+	}
+
+	var deltas []string
+	resp, err = client.Stream(context.Background(), req, func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if !server.LastRequest().Stream {
+		t.Error("Stream did not call streamGenerateContent")
+	}
+	if want := []string{"Hello", " there!"}; !reflect.DeepEqual(deltas, want) {
+		t.Errorf("deltas = %q, want %q", deltas, want)
+	}
+	if resp.Content != "Hello there!" {
+		t.Errorf("Stream content = %q", resp.Content)
+	}
+}
+
+func TestBlockedReplies(t *testing.T) {
+	tests := []struct {
+		name  string
+		chunk geminitest.Chunk
+	}{
+		{"prompt blocked", geminitest.Chunk{BlockReason: genai.BlockedReasonSafety}},
+		{"reply stopped for safety", geminitest.Chunk{FinishReason: genai.FinishReasonSafety}},
+		{"recitation", geminitest.Chunk{FinishReason: genai.FinishReasonRecitation}},
+	}
+
+	server := geminitest.NewServer(t)
+	client := gemini.NewClientWithBaseURL(server.URL)
+	req := provider.ChatRequest{Messages: []storage.ChatMessage{{Role: "user", Content: "Hi"}}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.Respond(tt.chunk)
+			if resp, err := client.Chat(context.Background(), req); err == nil {
+				t.Errorf("Chat returned %q, want an error for a reply without text", resp.Content)
+			}
+			if resp, err := client.Stream(context.Background(), req, nil); err == nil {
+				t.Errorf("Stream returned %q, want an error for a reply without text", resp.Content)
+			}
+		})
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	server := geminitest.NewServer(t)
+	server.RespondError(http.StatusTooManyRequests, "RESOURCE_EXHAUSTED", "Resource has been exhausted (e.g. check quota).")
+	client := gemini.NewClientWithBaseURL(server.URL)
+
+	_, err := client.Chat(context.Background(), provider.ChatRequest{
+		Messages: []storage.ChatMessage{{Role: "user", Content: "Hi"}},
+	})
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want a genai.APIError", err)
+	}
+	if apiErr.Code != http.StatusTooManyRequests || apiErr.Status != "RESOURCE_EXHAUSTED" {
+		t.Errorf("error = %+v", apiErr)
+	}
+}
+
+func TestNoKey(t *testing.T) {
+	server := geminitest.NewServer(t)
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+
+	_, err := gemini.NewClientWithBaseURL(server.URL).Chat(context.Background(), provider.ChatRequest{
+		Messages: []storage.ChatMessage{{Role: "user", Content: "Hi"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "GEMINI_API_KEY") {
+		t.Errorf("error = %v, want one naming GEMINI_API_KEY", err)
+	}
+	if server.LastRequest() != nil {
+		t.Error("a request was sent without a key")
+	}
+}
+
+func TestFinishReasons(t *testing.T) {
+	tests := []struct {
+		reason genai.FinishReason
+		want   string
+	}{
+		{genai.FinishReasonStop, provider.FinishStop},
+		{genai.FinishReasonMaxTokens, provider.FinishLength},
+		{genai.FinishReasonSafety, "safety"},
+		{genai.FinishReasonUnspecified, ""},
+	}
+
+	server := geminitest.NewServer(t)
+	client := gemini.NewClientWithBaseURL(server.URL)
+	req := provider.ChatRequest{Messages: []storage.ChatMessage{{Role: "user", Content: "Write a long story"}}}
+	for _, tt := range tests {
+		t.Run(string(tt.reason), func(t *testing.T) {
+			// The reason arrives with the last chunk of a stream
+			server.Respond(geminitest.Chunk{Text: "Once upon"}, geminitest.Chunk{Text: " a time", FinishReason: tt.reason})
+
+			resp, err := client.Chat(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Chat: %v", err)
+			}
+			if resp.FinishReason != tt.want {
+				t.Errorf("Chat finish reason = %q, want %q", resp.FinishReason, tt.want)
+			}
+
+			resp, err = client.Stream(context.Background(), req, nil)
+			if err != nil {
+				t.Fatalf("Stream: %v", err)
+			}
+			if resp.FinishReason != tt.want || resp.Content != "Once upon a time" {
+				t.Errorf("Stream = %q finishing %q, want %q", resp.Content, resp.FinishReason, tt.want)
+			}
+		})
+	}
+}
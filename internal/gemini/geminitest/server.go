@@ -0,0 +1,198 @@
+// Package geminitest provides a fake Gemini API, so the gemini provider can
+// be exercised without network access or a key.
+package geminitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+// Chunk is one response of the model: a piece of text, a thought summary,
+// or a prompt blocked before any candidate was made. A streamed reply sends
+// each chunk as its own event.
+type Chunk struct {
+	Text         string
+	Thought      bool
+	FinishReason genai.FinishReason
+	BlockReason  genai.BlockedReason
+}
+
+// Request is a generateContent call as the server received it.
+type Request struct {
+	Model             string
+	Stream            bool
+	Contents          []*genai.Content
+	SystemInstruction *genai.Content
+	GenerationConfig  map[string]any
+}
+
+// Server answers generateContent and streamGenerateContent with the chunks
+// given to Respond, "Hello from the fake server." until then.
+type Server struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	chunks  []Chunk
+	failure *apiError
+	last    *Request
+}
+
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+// NewServer starts a fake server for the duration of the test and sets
+// GEMINI_API_KEY so the client sends requests to it.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	t.Setenv("GEMINI_API_KEY", "fake-key")
+
+	s := &Server{chunks: []Chunk{{Text: "Hello from the fake server.", FinishReason: genai.FinishReasonStop}}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1beta/models/{call}", s.handleGenerate)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Respond sets the chunks of the following replies. A reply that is not
+// streamed joins them into one candidate ending with the last finish
+// reason.
+func (s *Server) Respond(chunks ...Chunk) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunks = chunks
+	s.failure = nil
+}
+
+// RespondError makes the following calls fail with the error body Google
+// APIs use, e.g. 429 and "RESOURCE_EXHAUSTED".
+func (s *Server) RespondError(code int, status, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failure = &apiError{Code: code, Status: status, Message: message}
+}
+
+// LastRequest returns the most recent call, or nil if there was none.
+func (s *Server) LastRequest() *Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("x-goog-api-key") == "" {
+		writeError(w, &apiError{Code: http.StatusForbidden, Status: "PERMISSION_DENIED", Message: "Method doesn't allow unregistered callers"})
+		return
+	}
+
+	var body struct {
+		Contents          []*genai.Content `json:"contents"`
+		SystemInstruction *genai.Content   `json:"systemInstruction"`
+		GenerationConfig  map[string]any   `json:"generationConfig"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, &apiError{Code: http.StatusBadRequest, Status: "INVALID_ARGUMENT", Message: err.Error()})
+		return
+	}
+	model, method, _ := strings.Cut(r.PathValue("call"), ":")
+	req := &Request{
+		Model:             model,
+		Stream:            method == "streamGenerateContent",
+		Contents:          body.Contents,
+		SystemInstruction: body.SystemInstruction,
+		GenerationConfig:  body.GenerationConfig,
+	}
+	if problem := validate(req); problem != "" {
+		writeError(w, &apiError{Code: http.StatusBadRequest, Status: "INVALID_ARGUMENT", Message: problem})
+		return
+	}
+
+	s.mu.Lock()
+	s.last = req
+	chunks, failure := s.chunks, s.failure
+	s.mu.Unlock()
+
+	if failure != nil {
+		writeError(w, failure)
+		return
+	}
+
+	if !req.Stream {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response(chunks...))
+		return
+	}
+	if r.URL.Query().Get("alt") != "sse" {
+		writeError(w, &apiError{Code: http.StatusBadRequest, Status: "INVALID_ARGUMENT", Message: "only alt=sse streams are faked"})
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	flusher, _ := w.(http.Flusher)
+	for _, chunk := range chunks {
+		data, _ := json.Marshal(response(chunk))
+		fmt.Fprintf(w, "data: %s\r\n\r\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// response builds a GenerateContentResponse body from chunks. A blocked
+// prompt has feedback instead of candidates.
+func response(chunks ...Chunk) map[string]any {
+	var parts []map[string]any
+	var finishReason genai.FinishReason
+	for _, chunk := range chunks {
+		if chunk.BlockReason != "" {
+			return map[string]any{"promptFeedback": map[string]any{"blockReason": chunk.BlockReason}}
+		}
+		if chunk.Text != "" {
+			parts = append(parts, map[string]any{"text": chunk.Text, "thought": chunk.Thought})
+		}
+		if chunk.FinishReason != "" {
+			finishReason = chunk.FinishReason
+		}
+	}
+
+	candidate := map[string]any{"index": 0}
+	if len(parts) > 0 {
+		candidate["content"] = map[string]any{"role": genai.RoleModel, "parts": parts}
+	}
+	if finishReason != "" {
+		candidate["finishReason"] = finishReason
+	}
+	return map[string]any{"candidates": []any{candidate}}
+}
+
+// validate applies the request rules of the real API that the client has
+// to get right.
+func validate(req *Request) string {
+	if len(req.Contents) == 0 {
+		return "* GenerateContentRequest.contents: contents is not specified"
+	}
+	for i, content := range req.Contents {
+		if content.Role != genai.RoleUser && content.Role != genai.RoleModel {
+			return fmt.Sprintf("Please use a valid role: user, model. (contents[%d].role = %q)", i, content.Role)
+		}
+		if len(content.Parts) == 0 {
+			return fmt.Sprintf("* GenerateContentRequest.contents[%d].parts: contents.parts must not be empty.", i)
+		}
+	}
+	return ""
+}
+
+func writeError(w http.ResponseWriter, failure *apiError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(failure.Code)
+	json.NewEncoder(w).Encode(map[string]any{"error": failure})
+}
@@ -2,93 +2,323 @@ package groq
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
 )
 
-const apiURL = "https://api.groq.com/openai/v1/chat/completions"
+const (
+	defaultBaseURL = "https://api.groq.com"
+	// apiPath is where Groq serves its OpenAI-compatible API
+	apiPath = "/openai/v1"
 
-var (
-	geminiURL = "https://api.gemini.com/v1/"
+	defaultModel              = "llama-3.3-70b-versatile"
+	defaultTranscriptionModel = "whisper-large-v3-turbo"
 )
-var (
-	currentModel    = "llama3-70b-8192"
-	availableModels = map[string]string{
-		"llama3-70b-8192":                       "Llama 3 70B",
-		"llama3-8b-8192":                        "Llama 3 8B",
-		"mixtral-8x7b-32768":                    "Mixtral 8x7B",
-		"gemma-7b-it":                           "Gemma 7B",
-		"llama3-groq-70b-8192-tool-use-preview": "Llama 3 70B Tools",
-		"llama3-groq-8b-8192-tool-use-preview":  "Llama 3 8B Tools",
+
+// Client talks to an OpenAI-compatible chat completions API: Groq's, or
+// the endpoint of a profile.
+type Client struct {
+	name string
+	// label names the endpoint in messages to the user
+	label            string
+	apiURL           string
+	modelsURL        string
+	transcriptionURL string
+	defaultModel     string
+	// transcriptionModel is the speech model used when none is asked for
+	transcriptionModel string
+	// keyEnv and keyFile are where the API key comes from. With neither,
+	// requests are sent without one.
+	keyEnv  string
+	keyFile string
+	// keySource tells the user where to fix a rejected key.
+	keySource string
+	headers   map[string]string
+	// models is the allow-list of model IDs, or nil to offer any.
+	models []string
+	// cacheFile keeps the model listing between runs. Without one the
+	// listing is fetched every time.
+	cacheFile   string
+	httpClient  *http.Client
+	maxAttempts int
+
+	mu        sync.Mutex
+	rateLimit provider.RateLimit
+}
+
+// NewClient returns a Groq client for GROQ_BASE_URL, falling back to the
+// public endpoint. The API key is read from GROQ_API_KEY on every request,
+// and GROQ_MAX_ATTEMPTS caps how often a failed request is tried.
+func NewClient() *Client {
+	return NewClientWithBaseURL(os.Getenv("GROQ_BASE_URL"))
+}
+
+// NewClientWithBaseURL returns a Groq client that sends requests to
+// baseURL instead of the public endpoint, e.g. a groqtest server. Only the
+// public endpoint's model list is cached.
+func NewClientWithBaseURL(baseURL string) *Client {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	cacheFile := ""
+	if baseURL == "" || baseURL == defaultBaseURL {
+		baseURL = defaultBaseURL
+		cacheFile = modelCacheFile
 	}
-)
 
-func GetAvailableModels() map[string]string {
-	return availableModels
+	return &Client{
+		name:               "groq",
+		label:              "Groq",
+		apiURL:             baseURL + apiPath + "/chat/completions",
+		modelsURL:          baseURL + apiPath + "/models",
+		transcriptionURL:   baseURL + apiPath + "/audio/transcriptions",
+		defaultModel:       defaultModel,
+		transcriptionModel: defaultTranscriptionModel,
+		keyEnv:             "GROQ_API_KEY",
+		keySource:          "GROQ_API_KEY in your .env file",
+		cacheFile:          cacheFile,
+		httpClient:         &http.Client{},
+		maxAttempts:        maxAttemptsFromEnv(),
+	}
+}
+
+func (c *Client) Name() string {
+	return c.name
 }
 
-func GetCurrentModel() string {
-	return currentModel
+func (c *Client) DefaultModel() string {
+	return c.defaultModel
 }
 
-func SetModel(model string) error {
-	if _, exists := availableModels[model]; !exists {
-		return fmt.Errorf("model %s not available", model)
+// Configured reports whether the API key can be found, or none is needed.
+func (c *Client) Configured() bool {
+	_, err := c.apiKey()
+	return err == nil
+}
+
+func (c *Client) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Streaming: true, Tools: true, Vision: true}
+}
+
+// Chat sends the whole chat history so the model can see earlier turns,
+// and returns the assistant's reply.
+func (c *Client) Chat(ctx context.Context, req provider.ChatRequest) (*provider.ChatResponse, error) {
+	messages := toMessages(req.Messages)
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages to send")
 	}
-	currentModel = model
-	return nil
+
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		return c.newRequest(ctx, req, messages, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed Response
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, err
+	}
+
+	if len(parsed.Choices) == 0 {
+		return nil, fmt.Errorf("no response recieved")
+	}
+
+	message := parsed.Choices[0].Message
+	return &provider.ChatResponse{
+		Content:      message.Content.Text,
+		Reasoning:    message.Reasoning,
+		ToolCalls:    fromToolCalls(message.ToolCalls),
+		Usage:        usage(parsed.Usage, parsed.XGroq),
+		FinishReason: parsed.Choices[0].FinishReason,
+	}, nil
+}
+
+// toMessages maps stored chat messages onto API messages. Local-only
+// entries such as "error" are left out, as are empty replies left behind
+// by cancelled requests.
+func toMessages(history []storage.ChatMessage) []Message {
+	messages := make([]Message, 0, len(history))
+	for _, msg := range history {
+		if msg.Content == "" && len(msg.ToolCalls) == 0 && msg.Role != "tool" {
+			continue
+		}
+		if msg.Role == "tool" && msg.Content == "" {
+			// Every tool call needs its result, or the request is rejected
+			msg.Content = "(no output)"
+		}
+		switch msg.Role {
+		case "user", "assistant", "system", "tool":
+			messages = append(messages, Message{
+				Role:       msg.Role,
+				Content:    toContent(msg),
+				ToolCalls:  toToolCalls(msg.ToolCalls),
+				ToolCallID: msg.ToolCallID,
+			})
+		}
+	}
+	return messages
+}
+
+// toContent sends the images attached to user messages as image_url parts
+// after the text.
+func toContent(msg storage.ChatMessage) Content {
+	images := msg.Images()
+	if msg.Role != "user" || len(images) == 0 {
+		return Content{Text: msg.Content}
+	}
+
+	parts := []ContentPart{{Type: "text", Text: msg.Content}}
+	for _, image := range images {
+		parts = append(parts, ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: image.DataURL()}})
+	}
+	return Content{Parts: parts}
+}
+
+func toTools(specs []provider.ToolSpec) []Tool {
+	var tools []Tool
+	for _, spec := range specs {
+		tools = append(tools, Tool{
+			Type: "function",
+			Function: Function{
+				Name:        spec.Name,
+				Description: spec.Description,
+				Parameters:  spec.Parameters,
+			},
+		})
+	}
+	return tools
 }
 
-func SendPrompt(prompt string) (string, error) {
-	apiKey := os.Getenv("GROQ_API_KEY")
-	if apiKey == "" {
-		return "", fmt.Errorf("API variable not set")
+func toToolCalls(calls []storage.ToolCall) []ToolCall {
+	var toolCalls []ToolCall
+	for i, call := range calls {
+		toolCalls = append(toolCalls, ToolCall{
+			Index:    i,
+			ID:       call.ID,
+			Type:     "function",
+			Function: FunctionCall{Name: call.Name, Arguments: call.Arguments},
+		})
+	}
+	return toolCalls
+}
+
+func fromToolCalls(toolCalls []ToolCall) []storage.ToolCall {
+	var calls []storage.ToolCall
+	for _, toolCall := range toolCalls {
+		calls = append(calls, storage.ToolCall{
+			ID:        toolCall.ID,
+			Name:      toolCall.Function.Name,
+			Arguments: toolCall.Function.Arguments,
+		})
+	}
+	return calls
+}
+
+// newRequest builds an authenticated chat completion request.
+func (c *Client) newRequest(ctx context.Context, chatReq provider.ChatRequest, messages []Message, stream bool) (*http.Request, error) {
+	model := chatReq.Model
+	if model == "" {
+		model = c.defaultModel
+	}
+	if !c.allows(model) {
+		return nil, fmt.Errorf("model %s is not in the models of profile %s", model, c.name)
 	}
 
 	payload := Request{
-		Model: currentModel,
-		Messages: []Message{
-			{Role: "user", Content: prompt},
-		},
+		Model:       model,
+		Messages:    messages,
+		Stream:      stream,
+		Temperature: chatReq.Params.Temperature,
+		TopP:        chatReq.Params.TopP,
+		MaxTokens:   chatReq.Params.MaxTokens,
+		Stop:        chatReq.Params.Stop,
+		Seed:        chatReq.Params.Seed,
+		Tools:       toTools(chatReq.Tools),
+	}
+	if stream {
+		payload.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+	if chatReq.JSON {
+		payload.ResponseFormat = &ResponseFormat{Type: "json_object"}
 	}
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.apiURL, bytes.NewBuffer(body))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
 	req.Header.Set("Content-Type", "application/json")
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	return req, nil
+}
 
-	if err != nil {
-		return "", err
+// apiKey returns the key from keyEnv, falling back to keyFile. It is empty
+// for endpoints that need no key.
+func (c *Client) apiKey() (string, error) {
+	if c.keyEnv == "" && c.keyFile == "" {
+		return "", nil
 	}
+	if c.keyEnv != "" {
+		if key := os.Getenv(c.keyEnv); key != "" {
+			return key, nil
+		}
+	}
+	if c.keyFile != "" {
+		data, err := os.ReadFile(c.keyFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key: %v", err)
+		}
+		if key := strings.TrimSpace(string(data)); key != "" {
+			return key, nil
+		}
+		return "", fmt.Errorf("API key file %s is empty", c.keyFile)
+	}
+	return "", fmt.Errorf("%s not set", c.keyEnv)
+}
 
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
+// authorize adds the API key, if any, and the extra headers to req.
+func (c *Client) authorize(req *http.Request) error {
+	apiKey, err := c.apiKey()
 	if err != nil {
-		return "", err
+		return err
 	}
-
-	var parsed Response
-	if err := json.Unmarshal(respBody, &parsed); err != nil {
-		return "", err
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
 	}
-
-	if len(parsed.Choices) == 0 {
-		return "", fmt.Errorf("no response recieved")
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
 	}
+	return nil
+}
 
-	return parsed.Choices[0].Message.Content, nil
+// allows reports whether model may be used. Without an allow-list, and for
+// an unset model the server picks itself, any model is allowed.
+func (c *Client) allows(model string) bool {
+	return len(c.models) == 0 || model == "" || slices.Contains(c.models, model)
 }
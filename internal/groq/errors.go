@@ -0,0 +1,84 @@
+package groq
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// APIError is an error response from the Groq API, or another endpoint
+// using the OpenAI {"error": {"message", "type", "code"}} body.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Code       string
+	Message    string
+	// Provider names the endpoint, and KeySource where its key is set,
+	// for the hint.
+	Provider  string
+	KeySource string
+}
+
+func (e *APIError) Error() string {
+	detail := fmt.Sprintf("HTTP %d", e.StatusCode)
+	if e.Code != "" {
+		detail += ", " + e.Code
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, detail)
+}
+
+// Hint suggests how the user can fix the error.
+func (e *APIError) Hint() string {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.Code == "invalid_api_key":
+		if e.KeySource == "" {
+			return "The API key was rejected."
+		}
+		return "The API key was rejected. Check " + e.KeySource + "."
+	case e.Code == "model_decommissioned" || e.Code == "model_not_found":
+		return "This model is no longer served. Pick another one with Ctrl+-."
+	case e.Code == "context_length_exceeded" || strings.Contains(e.Message, "context length"):
+		return "The conversation is too long for this model. Start a new chat with Ctrl+N or pick a model with a larger context window."
+	case e.StatusCode == http.StatusTooManyRequests || e.Code == "rate_limit_exceeded":
+		return "You are being rate limited. Wait a moment before retrying."
+	case e.StatusCode >= 500:
+		if e.Provider == "" {
+			return "The server is having trouble right now. Try again shortly."
+		}
+		return e.Provider + " is having trouble right now. Try again shortly."
+	}
+	return ""
+}
+
+// statusError turns a failed response into an *APIError, falling back to
+// the raw body when it is not the usual error JSON.
+func (c *Client) statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+
+	apiErr := &APIError{StatusCode: resp.StatusCode, Provider: c.label, KeySource: c.keySource}
+
+	var parsed ErrorResponse
+	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Error.Message != "" {
+		apiErr.Type = parsed.Error.Type
+		apiErr.Code = codeString(parsed.Error.Code)
+		apiErr.Message = parsed.Error.Message
+		return apiErr
+	}
+
+	apiErr.Message = strings.TrimSpace(string(body))
+	if apiErr.Message == "" {
+		apiErr.Message = resp.Status
+	}
+	return apiErr
+}
+
+// codeString accepts the error code as either a JSON string or number.
+func codeString(raw json.RawMessage) string {
+	var code string
+	if err := json.Unmarshal(raw, &code); err == nil {
+		return code
+	}
+	return strings.Trim(string(raw), `"`)
+}
@@ -0,0 +1,190 @@
+// Package groqtest provides a fake Groq server for the transcription
+// endpoint, so audio input can be exercised without network access or a
+// key.
+package groqtest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/groq"
+)
+
+// maxUpload mirrors the upload limit of the free tier.
+const maxUpload = 25 << 20
+
+// audioFormats are the file extensions the real endpoint accepts.
+var audioFormats = []string{".flac", ".mp3", ".mp4", ".mpeg", ".mpga", ".m4a", ".ogg", ".opus", ".wav", ".webm"}
+
+// Server is a fake Groq API. Every upload to
+// /openai/v1/audio/transcriptions is answered with the same transcript.
+// Point groq.NewClientWithBaseURL at its URL, with GROQ_API_KEY set to
+// anything.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	transcript string
+	failure    *groq.ErrorResponse
+	status     int
+	uploads    []Upload
+}
+
+// Upload is a transcription request as the server received it.
+type Upload struct {
+	FileName       string
+	Size           int
+	Model          string
+	Language       string
+	ResponseFormat string
+}
+
+// NewServer starts a fake server transcribing every file as "Hello from
+// the fake server." Call Close when done.
+func NewServer() *Server {
+	s := &Server{transcript: "Hello from the fake server."}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /openai/v1/audio/transcriptions", s.handleTranscription)
+	mux.HandleFunc("GET /openai/v1/models", s.handleModels)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetTranscript sets the text of the following transcriptions.
+func (s *Server) SetTranscript(transcript string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transcript = transcript
+	s.failure = nil
+}
+
+// Fail makes the following requests fail with an error body of the given
+// status, code and message.
+func (s *Server) Fail(status int, code, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+	failure := errorBody(code, message)
+	s.failure = &failure
+}
+
+// Uploads returns the transcription requests received so far.
+func (s *Server) Uploads() []Upload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Upload{}, s.uploads...)
+}
+
+func (s *Server) handleTranscription(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUpload+1<<20)
+	if err := r.ParseMultipartForm(maxUpload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "request must be multipart/form-data: "+err.Error())
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "file: field required")
+		return
+	}
+	defer file.Close()
+	audio, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	upload := Upload{
+		FileName:       header.Filename,
+		Size:           len(audio),
+		Model:          r.FormValue("model"),
+		Language:       r.FormValue("language"),
+		ResponseFormat: r.FormValue("response_format"),
+	}
+	switch {
+	case upload.Model == "":
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "model: field required")
+		return
+	case upload.Size == 0:
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "file is empty")
+		return
+	case !isAudio(upload.FileName):
+		writeError(w, http.StatusBadRequest, "invalid_request_error",
+			"file must be one of the following types: "+strings.Join(audioFormats, ", "))
+		return
+	}
+
+	s.mu.Lock()
+	s.uploads = append(s.uploads, upload)
+	transcript, failure, status := s.transcript, s.failure, s.status
+	s.mu.Unlock()
+
+	if failure != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(failure)
+		return
+	}
+
+	if upload.ResponseFormat == "text" {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, transcript)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"text":   transcript,
+		"x_groq": map[string]string{"id": "req_fake"},
+	})
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"object": "list",
+		"data": []map[string]any{
+			{"id": "llama-fake", "object": "model", "active": true, "context_window": 8192},
+			{"id": "whisper-large-v3-turbo", "object": "model", "active": true, "context_window": 448},
+		},
+	})
+}
+
+// authorized checks for the bearer token the real API insists on.
+func authorized(w http.ResponseWriter, r *http.Request) bool {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		writeError(w, http.StatusUnauthorized, "invalid_api_key", "Invalid API Key")
+		return false
+	}
+	return true
+}
+
+func isAudio(fileName string) bool {
+	return slices.Contains(audioFormats, strings.ToLower(filepath.Ext(fileName)))
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorBody(code, message))
+}
+
+func errorBody(code, message string) groq.ErrorResponse {
+	var body groq.ErrorResponse
+	body.Error.Type = "invalid_request_error"
+	body.Error.Code, _ = json.Marshal(code)
+	body.Error.Message = message
+	return body
+}
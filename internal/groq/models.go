@@ -0,0 +1,229 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/config"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+)
+
+const (
+	modelCacheFile = "groq_models.json"
+	modelCacheTTL  = 24 * time.Hour
+)
+
+// modelCache is the on-disk copy of the last model listing.
+type modelCache struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Models    []string  `json:"models"`
+	// Retired lists models that were listed before but have since
+	// disappeared upstream.
+	Retired []string `json:"retired,omitempty"`
+	// ContextWindows maps model IDs to their context length in tokens.
+	ContextWindows map[string]int `json:"context_windows,omitempty"`
+}
+
+// ListModels returns the chat models the endpoint currently serves, named
+// by the user's aliases where set. Models that disappeared upstream, and
+// aliases for models it does not serve, are included but flagged
+// unavailable. With an allow-list only those models are offered, and they
+// are all offered when the endpoint cannot list its models.
+func (c *Client) ListModels(ctx context.Context) ([]provider.Model, error) {
+	cache, err := c.loadModels(ctx)
+	if err != nil && len(c.models) == 0 {
+		return nil, err
+	}
+	if len(c.models) > 0 {
+		cache = restrict(cache, c.models)
+	}
+
+	// A broken aliases file should not hide the models themselves
+	aliases, _ := config.LoadAliases()
+	return buildModels(cache, aliases.For(c.Name())), nil
+}
+
+// loadModels returns the cached model listing, refreshing it from the API
+// once it is older than modelCacheTTL. A stale cache is used when the
+// refresh fails, e.g. while offline. Clients without a cache file always
+// ask the API.
+func (c *Client) loadModels(ctx context.Context) (*modelCache, error) {
+	if c.cacheFile == "" {
+		live, windows, err := c.fetchModels(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &modelCache{FetchedAt: time.Now(), Models: live, ContextWindows: windows}, nil
+	}
+
+	var cache modelCache
+	if err := config.LoadCache(c.cacheFile, &cache); err != nil {
+		cache = modelCache{}
+	}
+
+	if len(cache.Models) > 0 && time.Since(cache.FetchedAt) < modelCacheTTL {
+		return &cache, nil
+	}
+
+	live, windows, err := c.fetchModels(ctx)
+	if err != nil {
+		if len(cache.Models) > 0 {
+			return &cache, nil
+		}
+		return nil, err
+	}
+
+	cache = mergeListing(cache, live)
+	cache.ContextWindows = windows
+	if err := config.SaveCache(c.cacheFile, &cache); err != nil {
+		return nil, fmt.Errorf("failed to cache model list: %v", err)
+	}
+	return &cache, nil
+}
+
+// fetchModels asks the API for the chat models it currently serves, along
+// with their context windows.
+func (c *Client) fetchModels(ctx context.Context) ([]string, map[string]int, error) {
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", c.modelsURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.authorize(req); err != nil {
+			return nil, err
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var parsed ModelsResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse model list: %v", err)
+	}
+
+	var models []string
+	windows := map[string]int{}
+	for _, m := range parsed.Data {
+		if m.Active != nil && !*m.Active {
+			continue
+		}
+		if !isChatModel(m.ID) {
+			continue
+		}
+		models = append(models, m.ID)
+		if m.ContextWindow > 0 {
+			windows[m.ID] = m.ContextWindow
+		}
+	}
+	sort.Strings(models)
+	return models, windows, nil
+}
+
+// isChatModel filters out the speech models served from the same list.
+func isChatModel(id string) bool {
+	return !strings.Contains(id, "whisper") && !strings.Contains(id, "tts")
+}
+
+// mergeListing replaces the cached listing with a fresh one and remembers
+// every model that has disappeared since.
+func mergeListing(previous modelCache, live []string) modelCache {
+	isLive := make(map[string]bool, len(live))
+	for _, id := range live {
+		isLive[id] = true
+	}
+
+	seen := map[string]bool{}
+	var retired []string
+	known := append(append([]string{}, previous.Models...), previous.Retired...)
+	for _, id := range known {
+		if isLive[id] || seen[id] {
+			continue
+		}
+		seen[id] = true
+		retired = append(retired, id)
+	}
+	sort.Strings(retired)
+
+	return modelCache{
+		FetchedAt: time.Now(),
+		Models:    live,
+		Retired:   retired,
+	}
+}
+
+// restrict limits a listing to the allowed models, in their given order.
+// Allowed models missing from a listing are flagged unavailable; without a
+// listing they are all assumed to be served.
+func restrict(cache *modelCache, allowed []string) *modelCache {
+	if cache == nil {
+		return &modelCache{Models: allowed}
+	}
+
+	live := make(map[string]bool, len(cache.Models))
+	for _, id := range cache.Models {
+		live[id] = true
+	}
+
+	restricted := &modelCache{FetchedAt: cache.FetchedAt, ContextWindows: cache.ContextWindows}
+	for _, id := range allowed {
+		if live[id] {
+			restricted.Models = append(restricted.Models, id)
+		} else {
+			restricted.Retired = append(restricted.Retired, id)
+		}
+	}
+	return restricted
+}
+
+// buildModels turns a listing into selectable models: live models first,
+// then unavailable ones.
+func buildModels(cache *modelCache, aliases map[string]string) []provider.Model {
+	name := func(id string) string {
+		if alias, exists := aliases[id]; exists && alias != "" {
+			return alias
+		}
+		return id
+	}
+
+	listed := map[string]bool{}
+	var models []provider.Model
+	for _, id := range cache.Models {
+		listed[id] = true
+		models = append(models, provider.Model{
+			ID:            id,
+			Name:          name(id),
+			ContextWindow: cache.ContextWindows[id],
+			Vision:        provider.IsVisionModel(id),
+		})
+	}
+
+	unavailable := append([]string{}, cache.Retired...)
+	for id := range aliases {
+		unavailable = append(unavailable, id)
+	}
+	sort.Strings(unavailable)
+
+	for _, id := range unavailable {
+		if listed[id] {
+			continue
+		}
+		listed[id] = true
+		models = append(models, provider.Model{ID: id, Name: name(id), Unavailable: true})
+	}
+
+	return models
+}
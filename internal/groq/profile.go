@@ -0,0 +1,48 @@
+package groq
+
+import (
+	"net/http"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/config"
+)
+
+// NewProfileClient returns a client for the OpenAI-compatible endpoint of
+// profile, registered under the profile's name. Its model listing is not
+// cached, since local servers change what they serve whenever a model is
+// loaded.
+func NewProfileClient(profile config.Profile) *Client {
+	keySource := profile.APIKeyEnv
+	if profile.APIKeyFile != "" {
+		if keySource != "" {
+			keySource += " or "
+		}
+		keySource += profile.APIKeyFile
+	}
+
+	defaultModel := profile.DefaultModel
+	if defaultModel == "" && len(profile.Models) > 0 {
+		defaultModel = profile.Models[0]
+	}
+
+	transcriptionModel := profile.TranscriptionModel
+	if transcriptionModel == "" {
+		transcriptionModel = "whisper-1"
+	}
+
+	return &Client{
+		name:               profile.Name,
+		label:              profile.Name,
+		apiURL:             profile.BaseURL + "/chat/completions",
+		modelsURL:          profile.BaseURL + "/models",
+		transcriptionURL:   profile.BaseURL + "/audio/transcriptions",
+		defaultModel:       defaultModel,
+		transcriptionModel: transcriptionModel,
+		keyEnv:             profile.APIKeyEnv,
+		keyFile:            profile.APIKeyFile,
+		keySource:          keySource,
+		headers:            profile.Headers,
+		models:             profile.Models,
+		httpClient:         &http.Client{},
+		maxAttempts:        maxAttemptsFromEnv(),
+	}
+}
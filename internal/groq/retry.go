@@ -0,0 +1,179 @@
+package groq
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+)
+
+const (
+	defaultMaxAttempts = 3
+	baseRetryDelay     = time.Second
+	maxBackoffDelay    = 20 * time.Second
+	// maxRetryWait is the longest wait worth sitting through. Longer
+	// retry-after values, such as a daily token limit, fail right away.
+	maxRetryWait = time.Minute
+)
+
+// maxAttemptsFromEnv reads GROQ_MAX_ATTEMPTS, the total number of tries
+// per request including the first.
+func maxAttemptsFromEnv() int {
+	attempts, err := strconv.Atoi(os.Getenv("GROQ_MAX_ATTEMPTS"))
+	if err != nil || attempts < 1 {
+		return defaultMaxAttempts
+	}
+	return attempts
+}
+
+// do sends the request built by newReq, retrying rate-limited and
+// server-side failures with exponential backoff and jitter. The request is
+// rebuilt for every attempt so its body can be sent again. The caller must
+// close the body of the returned response, which always has status 200.
+func (c *Client) do(ctx context.Context, newReq func() (*http.Request, error)) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		req, err := newReq()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			if attempt >= c.maxAttempts {
+				return nil, err
+			}
+			if err := sleep(ctx, backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		c.recordRateLimit(resp.Header)
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+
+		wait := retryDelay(resp.Header, attempt)
+		if !isRetryable(resp.StatusCode) || attempt >= c.maxAttempts || wait > maxRetryWait {
+			defer resp.Body.Close()
+			return nil, c.statusError(resp)
+		}
+
+		resp.Body.Close()
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func isRetryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay prefers the server's retry-after header, then the reset time
+// of whichever rate limit is exhausted, and falls back to backoff.
+func retryDelay(header http.Header, attempt int) time.Duration {
+	if value := header.Get("retry-after"); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds * float64(time.Second))
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(time.Until(at), 0)
+		}
+	}
+
+	limit := parseRateLimit(header)
+	var wait time.Duration
+	if limit.LimitRequests > 0 && limit.RemainingRequests == 0 {
+		wait = max(wait, limit.ResetRequests)
+	}
+	if limit.LimitTokens > 0 && limit.RemainingTokens == 0 {
+		wait = max(wait, limit.ResetTokens)
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	return backoff(attempt)
+}
+
+// backoff doubles the delay with every attempt and picks a random point in
+// its upper half, so clients that failed together do not retry together.
+func backoff(attempt int) time.Duration {
+	delay := baseRetryDelay << (attempt - 1)
+	if delay <= 0 || delay > maxBackoffDelay {
+		delay = maxBackoffDelay
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseRateLimit reads Groq's x-ratelimit-* headers. Headers that are
+// missing or malformed are left at zero.
+func parseRateLimit(header http.Header) provider.RateLimit {
+	atoi := func(name string) int {
+		n, _ := strconv.Atoi(header.Get(name))
+		return n
+	}
+	duration := func(name string) time.Duration {
+		d, _ := time.ParseDuration(header.Get(name))
+		return d
+	}
+
+	return provider.RateLimit{
+		LimitRequests:     atoi("x-ratelimit-limit-requests"),
+		RemainingRequests: atoi("x-ratelimit-remaining-requests"),
+		ResetRequests:     duration("x-ratelimit-reset-requests"),
+		LimitTokens:       atoi("x-ratelimit-limit-tokens"),
+		RemainingTokens:   atoi("x-ratelimit-remaining-tokens"),
+		ResetTokens:       duration("x-ratelimit-reset-tokens"),
+	}
+}
+
+// recordRateLimit remembers the quota reported on the latest response.
+func (c *Client) recordRateLimit(header http.Header) {
+	limit := parseRateLimit(header)
+	if limit.LimitRequests == 0 && limit.LimitTokens == 0 {
+		return
+	}
+	limit.UpdatedAt = time.Now()
+
+	c.mu.Lock()
+	c.rateLimit = limit
+	c.mu.Unlock()
+}
+
+// RateLimit returns the quota reported on the latest response, and false
+// if no response has carried rate-limit headers yet.
+func (c *Client) RateLimit() (provider.RateLimit, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.rateLimit, !c.rateLimit.UpdatedAt.IsZero()
+}
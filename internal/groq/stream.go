@@ -0,0 +1,130 @@
+package groq
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+)
+
+// Stream sends the chat history with streaming enabled and calls onDelta
+// for every chunk of text as it arrives. If ctx is cancelled the text
+// received so far is returned along with the context's error.
+func (c *Client) Stream(ctx context.Context, req provider.ChatRequest, onDelta func(string)) (*provider.ChatResponse, error) {
+	messages := toMessages(req.Messages)
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages to send")
+	}
+
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		return c.newRequest(ctx, req, messages, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	reply, err := readStream(resp.Body, onDelta)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+	return reply, err
+}
+
+// readStream parses an OpenAI-style server-sent event stream. The returned
+// response is never nil and holds whatever arrived before any error.
+func readStream(r io.Reader, onDelta func(string)) (*provider.ChatResponse, error) {
+	var reply, reasoning strings.Builder
+	var toolCalls []ToolCall
+	result := &provider.ChatResponse{}
+	partial := func() *provider.ChatResponse {
+		result.Content = reply.String()
+		result.Reasoning = reasoning.String()
+		result.ToolCalls = fromToolCalls(toolCalls)
+		return result
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			// Blank separators, comments and other SSE fields
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var failure ErrorResponse
+		if json.Unmarshal([]byte(data), &failure) == nil && failure.Error.Message != "" {
+			return partial(), &APIError{
+				StatusCode: http.StatusOK,
+				Type:       failure.Error.Type,
+				Code:       codeString(failure.Error.Code),
+				Message:    failure.Error.Message,
+			}
+		}
+
+		var chunk StreamResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return partial(), fmt.Errorf("failed to parse stream chunk: %v", err)
+		}
+		if u := usage(chunk.Usage, chunk.XGroq); u != nil {
+			result.Usage = u
+		}
+
+		for _, choice := range chunk.Choices {
+			toolCalls = mergeToolCalls(toolCalls, choice.Delta.ToolCalls)
+			reasoning.WriteString(choice.Delta.Reasoning)
+			if choice.FinishReason != "" {
+				result.FinishReason = choice.FinishReason
+			}
+			if choice.Delta.Content == "" {
+				continue
+			}
+			reply.WriteString(choice.Delta.Content)
+			if onDelta != nil {
+				onDelta(choice.Delta.Content)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return partial(), err
+	}
+
+	if reply.Len() == 0 && len(toolCalls) == 0 {
+		return partial(), fmt.Errorf("no response recieved")
+	}
+
+	return partial(), nil
+}
+
+// mergeToolCalls folds streamed tool call fragments into the calls seen so
+// far. The first fragment of a call carries its ID and name; later ones
+// append to its arguments.
+func mergeToolCalls(calls []ToolCall, fragments []ToolCall) []ToolCall {
+	for _, fragment := range fragments {
+		for len(calls) <= fragment.Index {
+			calls = append(calls, ToolCall{Index: len(calls)})
+		}
+		call := &calls[fragment.Index]
+		if fragment.ID != "" {
+			call.ID = fragment.ID
+		}
+		if fragment.Function.Name != "" {
+			call.Function.Name = fragment.Function.Name
+		}
+		call.Function.Arguments += fragment.Function.Arguments
+	}
+	return calls
+}
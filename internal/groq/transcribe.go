@@ -0,0 +1,95 @@
+package groq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+)
+
+// maxAudioSize is the largest file the free tier accepts. Larger ones are
+// refused before uploading them.
+const maxAudioSize = 25 << 20
+
+// Transcribe uploads an audio file to the Whisper transcription endpoint
+// as multipart/form-data and returns the text spoken in it.
+func (c *Client) Transcribe(ctx context.Context, req provider.TranscriptionRequest) (*provider.Transcription, error) {
+	if len(req.Audio) == 0 {
+		return nil, fmt.Errorf("no audio to transcribe")
+	}
+	if len(req.Audio) > maxAudioSize {
+		return nil, fmt.Errorf("%s is larger than the %dMB upload limit", req.FileName, maxAudioSize>>20)
+	}
+
+	model := req.Model
+	if model == "" {
+		model = c.transcriptionModel
+	}
+
+	body, contentType, err := transcriptionForm(req, model)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", c.transcriptionURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if err := c.authorize(httpReq); err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Content-Type", contentType)
+		return httpReq, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var parsed TranscriptionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse transcription: %v", err)
+	}
+
+	text := strings.TrimSpace(parsed.Text)
+	if text == "" {
+		return nil, fmt.Errorf("no speech recognized in %s", req.FileName)
+	}
+	return &provider.Transcription{Text: text, Model: model}, nil
+}
+
+// transcriptionForm builds the multipart body holding the audio file, the
+// model and the language if set, and returns it with its content type.
+func transcriptionForm(req provider.TranscriptionRequest, model string) ([]byte, string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	file, err := form.CreateFormFile("file", req.FileName)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := file.Write(req.Audio); err != nil {
+		return nil, "", err
+	}
+	if err := form.WriteField("model", model); err != nil {
+		return nil, "", err
+	}
+	if err := form.WriteField("response_format", "json"); err != nil {
+		return nil, "", err
+	}
+	if req.Language != "" {
+		if err := form.WriteField("language", req.Language); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), form.FormDataContentType(), nil
+}
@@ -0,0 +1,120 @@
+package groq_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/config"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/groq"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/groq/groqtest"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+)
+
+func TestTranscribeUpload(t *testing.T) {
+	t.Setenv("GROQ_API_KEY", "test-key")
+	t.Setenv("PROFILE_KEY", "test-key")
+	server := groqtest.NewServer()
+	defer server.Close()
+
+	profile := config.Profile{Name: "local", BaseURL: server.URL + "/openai/v1", APIKeyEnv: "PROFILE_KEY"}
+	tuned := profile
+	tuned.TranscriptionModel = "distil-whisper-large-v3-en"
+
+	tests := []struct {
+		name   string
+		client *groq.Client
+		req    provider.TranscriptionRequest
+		want   groqtest.Upload
+	}{
+		{
+			name:   "groq default model",
+			client: groq.NewClientWithBaseURL(server.URL),
+			req:    provider.TranscriptionRequest{FileName: "memo.m4a", Audio: []byte("abc")},
+			want:   groqtest.Upload{FileName: "memo.m4a", Size: 3, Model: "whisper-large-v3-turbo", ResponseFormat: "json"},
+		},
+		{
+			name:   "requested model and language",
+			client: groq.NewClientWithBaseURL(server.URL),
+			req:    provider.TranscriptionRequest{FileName: "Sprachnotiz.ogg", Audio: []byte("abcd"), Model: "whisper-large-v3", Language: "de"},
+			want:   groqtest.Upload{FileName: "Sprachnotiz.ogg", Size: 4, Model: "whisper-large-v3", Language: "de", ResponseFormat: "json"},
+		},
+		{
+			name:   "profile default model",
+			client: groq.NewProfileClient(profile),
+			req:    provider.TranscriptionRequest{FileName: "memo.wav", Audio: []byte("ab")},
+			want:   groqtest.Upload{FileName: "memo.wav", Size: 2, Model: "whisper-1", ResponseFormat: "json"},
+		},
+		{
+			name:   "profile transcription model",
+			client: groq.NewProfileClient(tuned),
+			req:    provider.TranscriptionRequest{FileName: "memo.wav", Audio: []byte("ab")},
+			want:   groqtest.Upload{FileName: "memo.wav", Size: 2, Model: "distil-whisper-large-v3-en", ResponseFormat: "json"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.client.Transcribe(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("Transcribe: %v", err)
+			}
+			if result.Text != "Hello from the fake server." || result.Model != tt.want.Model {
+				t.Errorf("result = %+v, want the fake transcript by %s", *result, tt.want.Model)
+			}
+
+			uploads := server.Uploads()
+			if got := uploads[len(uploads)-1]; got != tt.want {
+				t.Errorf("upload = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscribeFailures(t *testing.T) {
+	t.Setenv("GROQ_API_KEY", "test-key")
+	t.Setenv("GROQ_MAX_ATTEMPTS", "1")
+	server := groqtest.NewServer()
+	defer server.Close()
+	client := groq.NewClientWithBaseURL(server.URL)
+	memo := provider.TranscriptionRequest{FileName: "memo.mp3", Audio: []byte("abc")}
+
+	t.Run("error response", func(t *testing.T) {
+		server.Fail(http.StatusUnauthorized, "invalid_api_key", "Invalid API Key")
+		_, err := client.Transcribe(context.Background(), memo)
+
+		var apiErr *groq.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("error = %v, want a *groq.APIError", err)
+		}
+		if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Code != "invalid_api_key" {
+			t.Errorf("error = %+v", *apiErr)
+		}
+		if !strings.Contains(apiErr.Hint(), "GROQ_API_KEY") {
+			t.Errorf("hint = %q, want it to name GROQ_API_KEY", apiErr.Hint())
+		}
+	})
+
+	t.Run("silence", func(t *testing.T) {
+		server.SetTranscript(" \n")
+		if _, err := client.Transcribe(context.Background(), memo); err == nil || !strings.Contains(err.Error(), "no speech") {
+			t.Errorf("error = %v, want one saying no speech was recognized", err)
+		}
+	})
+
+	for name, audio := range map[string][]byte{
+		"empty":     nil,
+		"oversized": make([]byte, 25<<20+1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			before := len(server.Uploads())
+			if _, err := client.Transcribe(context.Background(), provider.TranscriptionRequest{FileName: "memo.mp3", Audio: audio}); err == nil {
+				t.Error("Transcribe accepted the file")
+			}
+			if len(server.Uploads()) != before {
+				t.Error("the file was uploaded")
+			}
+		})
+	}
+}
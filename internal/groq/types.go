@@ -1,17 +1,199 @@
 package groq
 
+import (
+	"encoding/json"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+)
+
 type Request struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
+	Model          string          `json:"model"`
+	Messages       []Message       `json:"messages"`
+	Stream         bool            `json:"stream,omitempty"`
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"`
+	Temperature    *float64        `json:"temperature,omitempty"`
+	TopP           *float64        `json:"top_p,omitempty"`
+	MaxTokens      *int            `json:"max_tokens,omitempty"`
+	Stop           []string        `json:"stop,omitempty"`
+	Seed           *int            `json:"seed,omitempty"`
+	Tools          []Tool          `json:"tools,omitempty"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ResponseFormat constrains the reply, e.g. {"type": "json_object"}.
+type ResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// StreamOptions asks for the usage block on the final streamed chunk.
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string     `json:"role"`
+	Content    Content    `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+	// Reasoning is returned by reasoning models that keep their thinking
+	// out of the content. It is never sent.
+	Reasoning string `json:"reasoning,omitempty"`
+}
+
+// Content is a message's content: plain text, or a list of parts when
+// images are attached. It is sent as a string unless it has parts.
+type Content struct {
+	Text  string
+	Parts []ContentPart
+}
+
+// ContentPart is a piece of a multimodal message, of type "text" or
+// "image_url".
+type ContentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL points at an image, here always a base64 data: URL.
+type ImageURL struct {
+	URL string `json:"url"`
+}
+
+func (c Content) MarshalJSON() ([]byte, error) {
+	if len(c.Parts) > 0 {
+		return json.Marshal(c.Parts)
+	}
+	return json.Marshal(c.Text)
+}
+
+// UnmarshalJSON accepts either form. The text of any parts is joined into
+// Text.
+func (c *Content) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*c = Content{}
+		return nil
+	}
+	if err := json.Unmarshal(data, &c.Text); err == nil {
+		c.Parts = nil
+		return nil
+	}
+	if err := json.Unmarshal(data, &c.Parts); err != nil {
+		return err
+	}
+	c.Text = ""
+	for _, part := range c.Parts {
+		c.Text += part.Text
+	}
+	return nil
+}
+
+// Tool offers a function to the model.
+type Tool struct {
+	Type     string   `json:"type"`
+	Function Function `json:"function"`
+}
+
+type Function struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// ToolCall is a function call requested by the model. When streamed, it
+// arrives in pieces that share an Index.
+type ToolCall struct {
+	Index    int          `json:"index"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
 }
 
 type Response struct {
 	Choices []struct {
-		Message Message `json:"message"`
+		Message      Message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage"`
+	XGroq *XGroq `json:"x_groq"`
+}
+
+// StreamResponse is a single server-sent event chunk of a streamed completion.
+type StreamResponse struct {
+	Choices []struct {
+		Delta struct {
+			Content   string     `json:"content"`
+			Reasoning string     `json:"reasoning"`
+			ToolCalls []ToolCall `json:"tool_calls"`
+		} `json:"delta"`
+		// Set on the last chunk of the choice
+		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
+	Usage *Usage `json:"usage"`
+	XGroq *XGroq `json:"x_groq"`
+}
+
+// Usage is the token accounting and timing of a completion.
+type Usage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	QueueTime        float64 `json:"queue_time"`
+	PromptTime       float64 `json:"prompt_time"`
+	CompletionTime   float64 `json:"completion_time"`
+	TotalTime        float64 `json:"total_time"`
+}
+
+// XGroq carries Groq-specific metadata. On streamed completions the usage
+// arrives here, on the final chunk.
+type XGroq struct {
+	ID    string `json:"id"`
+	Usage *Usage `json:"usage"`
+}
+
+// usage picks whichever usage block the response carried.
+func usage(u *Usage, x *XGroq) *storage.Usage {
+	if u == nil && x != nil {
+		u = x.Usage
+	}
+	if u == nil {
+		return nil
+	}
+	return &storage.Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+		QueueTime:        u.QueueTime,
+		TotalTime:        u.TotalTime,
+	}
+}
+
+// ModelsResponse is the body of GET /openai/v1/models.
+type ModelsResponse struct {
+	Data []struct {
+		ID            string `json:"id"`
+		OwnedBy       string `json:"owned_by"`
+		Active        *bool  `json:"active"`
+		ContextWindow int    `json:"context_window"`
+	} `json:"data"`
+}
+
+// TranscriptionResponse is the body of a transcription in the "json"
+// response format.
+type TranscriptionResponse struct {
+	Text string `json:"text"`
+}
+
+// ErrorResponse is the body of a failed request.
+type ErrorResponse struct {
+	Error struct {
+		Message string          `json:"message"`
+		Type    string          `json:"type"`
+		Code    json.RawMessage `json:"code"`
+	} `json:"error"`
 }
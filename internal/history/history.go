@@ -0,0 +1,188 @@
+// Package history fits a chat history into a model's context window, by
+// dropping or summarizing the oldest turns once it no longer fits.
+package history
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/reasoning"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+)
+
+// Strategies for trimming a history that outgrows the context window
+const (
+	StrategyDropOldest = "drop_oldest"
+	StrategyKeepPinned = "keep_pinned"
+	StrategySummarize  = "summarize"
+)
+
+// Strategies lists every strategy, the default first.
+var Strategies = []string{StrategyDropOldest, StrategyKeepPinned, StrategySummarize}
+
+const (
+	// messageOverhead approximates the tokens spent on each message's role
+	// and separators
+	messageOverhead = 4
+
+	// imageTokens approximates what an attached image costs. It varies
+	// with the model and the image size, but is rarely more.
+	imageTokens = 1500
+
+	// SummaryReserve is the room left for the summary note when the
+	// summarize strategy trims a history
+	SummaryReserve = 512
+
+	// MinBudget is the least history a request is given, so a reply limit
+	// close to the context window still leaves the recent turns in
+	MinBudget = 1024
+
+	summaryMaxTokens = 400
+	summaryPrompt    = "Summarize the conversation below for an assistant who will continue it. " +
+		"Keep facts, decisions, names, code identifiers and open questions. Use at most 200 words."
+)
+
+// EstimateTokens approximates the number of tokens in text at about four
+// characters per token. It errs on the high side for English prose.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// EstimateMessage approximates the tokens a message costs in a request.
+func EstimateMessage(msg storage.ChatMessage) int {
+	return EstimateTokens(msg.Content) + len(msg.Images())*imageTokens + messageOverhead
+}
+
+// EstimateTools approximates the tokens the tool definitions add to a
+// request.
+func EstimateTools(specs []provider.ToolSpec) int {
+	total := 0
+	for _, spec := range specs {
+		total += EstimateTokens(spec.Name+spec.Description+string(spec.Parameters)) + messageOverhead
+	}
+	return total
+}
+
+// Sendable returns the messages of history that are sent to providers,
+// leaving out local-only entries such as errors and empty replies, and the
+// reasoning of earlier replies. Tool results are kept even when empty, as
+// the call they answer cannot go without them.
+func Sendable(history []storage.ChatMessage) []storage.ChatMessage {
+	sendable := make([]storage.ChatMessage, 0, len(history))
+	for _, msg := range history {
+		if msg.Role == "assistant" {
+			msg.Content, _ = reasoning.SplitFor(msg.Model, msg.Content)
+			msg.Reasoning = ""
+		}
+		if msg.Content == "" && len(msg.ToolCalls) == 0 && msg.Role != "tool" {
+			continue
+		}
+		switch msg.Role {
+		case "user", "assistant", "system", "tool":
+			sendable = append(sendable, msg)
+		}
+	}
+	return sendable
+}
+
+// Fit trims messages until they fit in budget tokens, dropping the oldest
+// first. StrategyKeepPinned spares pinned messages; the other strategies drop a
+// prefix of the history. The latest message is always kept. Fit returns
+// the messages to send and how many were dropped.
+func Fit(messages []storage.ChatMessage, budget int, strategy string) ([]storage.ChatMessage, int) {
+	total := 0
+	for _, msg := range messages {
+		total += EstimateMessage(msg)
+	}
+	if total <= budget {
+		return messages, 0
+	}
+
+	last := len(messages) - 1
+	keep := make([]bool, len(messages))
+	for i := range keep {
+		keep[i] = true
+	}
+	for i := 0; i < last && total > budget; i++ {
+		if strategy == StrategyKeepPinned && messages[i].Pinned {
+			continue
+		}
+		keep[i] = false
+		total -= EstimateMessage(messages[i])
+	}
+
+	// A reply or tool result whose question was dropped would open the
+	// request with an orphaned turn, which providers reject
+	for i := 0; i < last; i++ {
+		if !keep[i] {
+			continue
+		}
+		if (messages[i].Role != "assistant" && messages[i].Role != "tool") || messages[i].Pinned {
+			break
+		}
+		keep[i] = false
+	}
+
+	kept := make([]storage.ChatMessage, 0, len(messages))
+	for i, msg := range messages {
+		if keep[i] {
+			kept = append(kept, msg)
+		}
+	}
+	return kept, len(messages) - len(kept)
+}
+
+// Summarize asks the model for a summary of messages, folding in previous,
+// the summary of the messages that came before them. The transcript is
+// trimmed to budget tokens if it would not fit the request itself.
+func Summarize(ctx context.Context, p provider.Provider, model, previous string, messages []storage.ChatMessage, budget int) (string, error) {
+	messages, _ = Fit(messages, budget-EstimateTokens(previous)-EstimateTokens(summaryPrompt), StrategyDropOldest)
+
+	var transcript strings.Builder
+	if previous != "" {
+		transcript.WriteString("Summary so far: " + previous + "\n\n")
+	}
+	for _, msg := range messages {
+		role := "User"
+		switch msg.Role {
+		case "assistant":
+			role = "Assistant"
+		case "system":
+			role = "System"
+		case "tool":
+			role = "Tool result"
+		}
+		transcript.WriteString(fmt.Sprintf("%s: %s\n\n", role, msg.Content))
+	}
+
+	maxTokens := summaryMaxTokens
+	resp, err := p.Chat(ctx, provider.ChatRequest{
+		Model: model,
+		Messages: []storage.ChatMessage{
+			{Role: "system", Content: summaryPrompt},
+			{Role: "user", Content: transcript.String()},
+		},
+		Params: storage.GenerationParams{MaxTokens: &maxTokens},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize earlier messages: %v", err)
+	}
+
+	summary := strings.TrimSpace(resp.Content)
+	if summary == "" {
+		return "", fmt.Errorf("failed to summarize earlier messages: empty summary")
+	}
+	return summary, nil
+}
+
+// SummaryNote wraps a summary in the synthetic system message sent in
+// place of the messages it covers.
+func SummaryNote(summary string) storage.ChatMessage {
+	return storage.ChatMessage{
+		Role:    "system",
+		Content: "Summary of the earlier conversation, which is no longer shown in full:\n" + summary,
+	}
+}
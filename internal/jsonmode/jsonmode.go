@@ -0,0 +1,134 @@
+// Package jsonmode asks models for JSON replies and checks them against a
+// JSON Schema, re-prompting with the problems found until a reply conforms.
+package jsonmode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/reasoning"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+)
+
+// MaxAttempts caps how many replies are requested before giving up on one
+// that conforms.
+const MaxAttempts = 3
+
+// maxProblems caps how many problems are quoted back to the model.
+const maxProblems = 10
+
+// ValidationError reports a reply that still did not conform after
+// MaxAttempts tries.
+type ValidationError struct {
+	Attempts int
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("reply did not match the JSON schema after %d attempts: %s", e.Attempts, strings.Join(e.Problems, "; "))
+}
+
+func (e *ValidationError) Hint() string {
+	return "The last reply is kept above. Try a more capable model, or loosen the schema."
+}
+
+// Instructions is the system note asking for JSON, quoting the schema when
+// there is one. Some providers refuse JSON mode unless the prompt
+// mentions JSON.
+func Instructions(schema json.RawMessage) string {
+	if len(schema) == 0 {
+		return "Reply with a single valid JSON object and nothing else."
+	}
+	return "Reply with a single valid JSON object and nothing else. It must conform to this JSON Schema:\n" + string(schema)
+}
+
+// Extract returns the JSON document in a reply, leaving out the thinking of
+// reasoning models and unwrapping a markdown code fence if the model added
+// one.
+func Extract(content string) string {
+	content, _ = reasoning.Split(content)
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimPrefix(content, "json")
+	content = strings.TrimSuffix(strings.TrimSpace(content), "```")
+	return strings.TrimSpace(content)
+}
+
+// Generate sends req in JSON mode and checks the reply against schema, or
+// only that it is JSON when schema is empty. A reply with problems is sent
+// back with a list of them and a request to fix it, up to MaxAttempts
+// times. onRetry is called before each new attempt so a caller showing the
+// streamed reply can clear it. Usage is totalled over all attempts.
+func Generate(ctx context.Context, p provider.Provider, req provider.ChatRequest, schema json.RawMessage, onDelta func(string), onRetry func(problems []string)) (*provider.ChatResponse, error) {
+	var compiled *Schema
+	if len(schema) > 0 {
+		var err error
+		if compiled, err = ParseSchema(schema); err != nil {
+			return nil, err
+		}
+	}
+
+	instructions := storage.ChatMessage{Role: "system", Content: Instructions(schema)}
+	messages := append([]storage.ChatMessage{instructions}, req.Messages...)
+	req.JSON = true
+	var total *storage.Usage
+
+	for attempt := 1; ; attempt++ {
+		req.Messages = messages
+
+		var resp *provider.ChatResponse
+		var err error
+		if p.Capabilities().Streaming {
+			resp, err = p.Stream(ctx, req, onDelta)
+		} else {
+			resp, err = p.Chat(ctx, req)
+		}
+		if resp != nil && resp.Usage != nil {
+			if total == nil {
+				total = &storage.Usage{}
+			}
+			total.Add(*resp.Usage)
+			resp.Usage = total
+		}
+		if err != nil {
+			return resp, err
+		}
+
+		problems := check(compiled, Extract(resp.Content))
+		if len(problems) == 0 {
+			return resp, nil
+		}
+		if len(problems) > maxProblems {
+			problems = append(problems[:maxProblems], fmt.Sprintf("and %d more", len(problems)-maxProblems))
+		}
+		if attempt == MaxAttempts {
+			return resp, &ValidationError{Attempts: attempt, Problems: problems}
+		}
+
+		if onRetry != nil {
+			onRetry(problems)
+		}
+		messages = append(messages,
+			storage.ChatMessage{Role: "assistant", Content: resp.Content},
+			storage.ChatMessage{Role: "user", Content: "That reply is invalid:\n- " + strings.Join(problems, "\n- ") +
+				"\nReply again with only the corrected JSON."},
+		)
+	}
+}
+
+func check(schema *Schema, content string) []string {
+	if schema == nil {
+		var value any
+		if err := json.Unmarshal([]byte(content), &value); err != nil {
+			return []string{fmt.Sprintf("not valid JSON: %v", err)}
+		}
+		return nil
+	}
+	return schema.Validate([]byte(content))
+}
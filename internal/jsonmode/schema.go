@@ -0,0 +1,241 @@
+package jsonmode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is the subset of JSON Schema that replies are checked against:
+// type, enum, const, properties, required, additionalProperties, items,
+// numeric bounds, string length and pattern, and array length. Other
+// keywords are ignored.
+type Schema struct {
+	Type                 schemaTypes        `json:"type"`
+	Enum                 []any              `json:"enum"`
+	Const                *any               `json:"const"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"-"`
+	Items                *Schema            `json:"items"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaTypes accepts both "type": "string" and "type": ["string", "null"].
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if json.Unmarshal(data, &single) == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = many
+	return nil
+}
+
+// ParseSchema reads a JSON Schema document.
+func ParseSchema(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	if err := schema.compile(data); err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	return &schema, nil
+}
+
+// compile fills in what encoding/json cannot: additionalProperties, which
+// may be a boolean or a schema (the latter is treated as allowed), and the
+// compiled pattern. It recurses into nested schemas.
+func (s *Schema) compile(data []byte) error {
+	var raw struct {
+		AdditionalProperties json.RawMessage            `json:"additionalProperties"`
+		Properties           map[string]json.RawMessage `json:"properties"`
+		Items                json.RawMessage            `json:"items"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var allowed bool
+	if json.Unmarshal(raw.AdditionalProperties, &allowed) == nil {
+		s.AdditionalProperties = &allowed
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern %q: %v", s.Pattern, err)
+		}
+		s.pattern = pattern
+	}
+	for name, property := range s.Properties {
+		if err := property.compile(raw.Properties[name]); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(raw.Items)
+	}
+	return nil
+}
+
+// Validate checks a JSON document against the schema and describes every
+// violation found, or returns nil when it conforms.
+func (s *Schema) Validate(data []byte) []string {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return []string{fmt.Sprintf("not valid JSON: %v", err)}
+	}
+
+	var problems []string
+	s.validate("$", value, &problems)
+	return problems
+}
+
+func (s *Schema) validate(path string, value any, problems *[]string) {
+	report := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return hasType(value, t) }) {
+		report("expected %s, got %s", strings.Join(s.Type, " or "), typeName(value))
+		return
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(option any) bool { return equal(option, value) }) {
+		report("must be one of %s", compact(s.Enum))
+	}
+	if s.Const != nil && !equal(*s.Const, value) {
+		report("must be %s", compact(*s.Const))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				report("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := s.Properties[name]; ok {
+				property.validate(path+"."+name, v[name], problems)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				report("unexpected property %q", name)
+			}
+		}
+
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			report("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			report("must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems)
+			}
+		}
+
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			report("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			report("must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			report("must match %s", s.Pattern)
+		}
+
+	case json.Number:
+		number, _ := v.Float64()
+		if s.Minimum != nil && number < *s.Minimum {
+			report("must be at least %g", *s.Minimum)
+		}
+		if s.Maximum != nil && number > *s.Maximum {
+			report("must be at most %g", *s.Maximum)
+		}
+	}
+}
+
+func hasType(value any, name string) bool {
+	switch v := value.(type) {
+	case nil:
+		return name == "null"
+	case bool:
+		return name == "boolean"
+	case string:
+		return name == "string"
+	case []any:
+		return name == "array"
+	case map[string]any:
+		return name == "object"
+	case json.Number:
+		if name == "number" {
+			return true
+		}
+		number, err := v.Float64()
+		return name == "integer" && err == nil && number == math.Trunc(number)
+	}
+	return false
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "number"
+}
+
+// equal compares JSON values, treating numbers by value.
+func equal(a, b any) bool {
+	return compact(a) == compact(b)
+}
+
+func compact(value any) string {
+	data, _ := json.Marshal(value)
+	var normalized any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if decoder.Decode(&normalized) != nil {
+		return string(data)
+	}
+	data, _ = json.Marshal(normalized)
+	return string(data)
+}
@@ -0,0 +1,305 @@
+// Package ollama implements a chat provider for a local Ollama server, so
+// the app can be used offline and without any API key.
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+)
+
+const (
+	defaultHost  = "http://localhost:11434"
+	defaultModel = "llama3.2"
+)
+
+// Client talks to Ollama's native /api/chat and /api/tags endpoints.
+type Client struct {
+	host       string
+	httpClient *http.Client
+}
+
+// NewClient returns a client for the host in OLLAMA_HOST, falling back to
+// the local default.
+func NewClient() *Client {
+	return NewClientWithHost(os.Getenv("OLLAMA_HOST"))
+}
+
+// NewClientWithHost returns a client for the given host. A host without a
+// scheme, such as "10.0.0.5:11434", is assumed to be plain HTTP.
+func NewClientWithHost(host string) *Client {
+	host = strings.TrimRight(strings.TrimSpace(host), "/")
+	if host == "" {
+		host = defaultHost
+	}
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+
+	return &Client{
+		host:       host,
+		httpClient: &http.Client{},
+	}
+}
+
+func (c *Client) Name() string {
+	return "ollama"
+}
+
+// DefaultModel returns OLLAMA_MODEL when set, named the way ListModels
+// names it.
+func (c *Client) DefaultModel() string {
+	if model := os.Getenv("OLLAMA_MODEL"); model != "" {
+		return strings.TrimSuffix(model, ":latest")
+	}
+	return defaultModel
+}
+
+// Configured is always true: a local server needs no API key.
+func (c *Client) Configured() bool {
+	return true
+}
+
+func (c *Client) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Streaming: true, Vision: true}
+}
+
+// ListModels returns the models pulled on the server.
+func (c *Client) ListModels(ctx context.Context) ([]provider.Model, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.host+"/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var parsed TagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse model list: %v", err)
+	}
+
+	models := make([]provider.Model, 0, len(parsed.Models))
+	for _, m := range parsed.Models {
+		// Ollama lists "llama3.2:latest" for what is pulled and requested
+		// as "llama3.2", so the default tag is left off
+		id := strings.TrimSuffix(m.Name, ":latest")
+		name := id
+		if m.Details.ParameterSize != "" {
+			name = fmt.Sprintf("%s (%s)", id, m.Details.ParameterSize)
+		}
+		vision := provider.IsVisionModel(id) ||
+			slices.Contains(m.Details.Families, "clip") || slices.Contains(m.Details.Families, "mllama")
+		models = append(models, provider.Model{ID: id, Name: name, Vision: vision})
+	}
+	return models, nil
+}
+
+// Chat sends the conversation and waits for the complete reply.
+func (c *Client) Chat(ctx context.Context, req provider.ChatRequest) (*provider.ChatResponse, error) {
+	resp, err := c.postChat(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var parsed Response
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+	if parsed.Error != "" {
+		return nil, fmt.Errorf("ollama: %s", parsed.Error)
+	}
+	if parsed.Message.Content == "" {
+		return nil, fmt.Errorf("no response recieved")
+	}
+
+	return &provider.ChatResponse{
+		Content:      parsed.Message.Content,
+		Reasoning:    parsed.Message.Thinking,
+		Usage:        parsed.usage(),
+		FinishReason: parsed.DoneReason,
+	}, nil
+}
+
+// Stream sends the conversation and calls onDelta for each chunk of the
+// newline-delimited JSON stream.
+func (c *Client) Stream(ctx context.Context, req provider.ChatRequest, onDelta func(string)) (*provider.ChatResponse, error) {
+	resp, err := c.postChat(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var reply, thinking strings.Builder
+	result := &provider.ChatResponse{}
+	err = readStream(resp.Body, func(chunk Response) {
+		if chunk.Done {
+			result.Usage = chunk.usage()
+			result.FinishReason = chunk.DoneReason
+		}
+		thinking.WriteString(chunk.Message.Thinking)
+		if chunk.Message.Content == "" {
+			return
+		}
+		reply.WriteString(chunk.Message.Content)
+		if onDelta != nil {
+			onDelta(chunk.Message.Content)
+		}
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+	if err == nil && reply.Len() == 0 {
+		err = fmt.Errorf("no response recieved")
+	}
+	result.Content = reply.String()
+	result.Reasoning = thinking.String()
+	return result, err
+}
+
+func (c *Client) postChat(ctx context.Context, req provider.ChatRequest, stream bool) (*http.Response, error) {
+	messages := toMessages(req.Messages)
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages to send")
+	}
+
+	model := req.Model
+	if model == "" {
+		model = c.DefaultModel()
+	}
+
+	payload := Request{
+		Model:    model,
+		Messages: messages,
+		Stream:   stream,
+	}
+	if req.JSON {
+		payload.Format = "json"
+	}
+	if params := req.Params; !params.IsZero() {
+		payload.Options = &Options{
+			Temperature: params.Temperature,
+			TopP:        params.TopP,
+			NumPredict:  params.MaxTokens,
+			Stop:        params.Stop,
+			Seed:        params.Seed,
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.host+"/api/chat", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, responseError(resp)
+	}
+	return resp, nil
+}
+
+// readStream parses Ollama's newline-delimited JSON stream, handing every
+// chunk to onChunk until the one marked done.
+func readStream(r io.Reader, onChunk func(Response)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk Response
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %v", err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("ollama: %s", chunk.Error)
+		}
+		onChunk(chunk)
+		if chunk.Done {
+			return nil
+		}
+	}
+
+	return scanner.Err()
+}
+
+// usage converts the counters Ollama reports on its final chunk.
+func (r Response) usage() *storage.Usage {
+	if !r.Done || (r.PromptEvalCount == 0 && r.EvalCount == 0) {
+		return nil
+	}
+	return &storage.Usage{
+		PromptTokens:     r.PromptEvalCount,
+		CompletionTokens: r.EvalCount,
+		TotalTokens:      r.PromptEvalCount + r.EvalCount,
+		TotalTime:        float64(r.TotalDuration) / 1e9,
+	}
+}
+
+// toMessages maps stored chat messages onto Ollama messages, leaving out
+// local-only and empty entries. Images attached to user messages are sent
+// along with them.
+func toMessages(history []storage.ChatMessage) []Message {
+	messages := make([]Message, 0, len(history))
+	for _, msg := range history {
+		if msg.Content == "" {
+			continue
+		}
+		switch msg.Role {
+		case "user", "assistant", "system":
+			message := Message{Role: msg.Role, Content: msg.Content}
+			if msg.Role == "user" {
+				for _, image := range msg.Images() {
+					message.Images = append(message.Images, image.Base64())
+				}
+			}
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// responseError turns a non-200 response into an error, using Ollama's
+// {"error": "..."} body when present.
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+
+	var parsed struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) == nil && parsed.Error != "" {
+		return fmt.Errorf("ollama: %s", parsed.Error)
+	}
+	return fmt.Errorf("ollama request failed (%s): %s", resp.Status, strings.TrimSpace(string(body)))
+}
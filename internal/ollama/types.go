@@ -0,0 +1,55 @@
+package ollama
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// Images are base64-encoded images for vision models
+	Images []string `json:"images,omitempty"`
+	// Thinking is the reasoning of thinking models, kept out of Content.
+	// It is never sent.
+	Thinking string `json:"thinking,omitempty"`
+}
+
+type Request struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+	Options  *Options  `json:"options,omitempty"`
+	// Format is "json" to constrain the reply to JSON
+	Format string `json:"format,omitempty"`
+}
+
+// Options holds the model parameters Ollama accepts per request.
+type Options struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  *int     `json:"num_predict,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+}
+
+// Response is both the non-streamed reply and a single streamed chunk.
+type Response struct {
+	Message Message `json:"message"`
+	Done    bool    `json:"done"`
+	Error   string  `json:"error,omitempty"`
+	// Why generation stopped, e.g. "stop" or "length"; set once done
+	DoneReason string `json:"done_reason,omitempty"`
+
+	// Set on the final chunk; durations are in nanoseconds
+	PromptEvalCount int   `json:"prompt_eval_count"`
+	EvalCount       int   `json:"eval_count"`
+	TotalDuration   int64 `json:"total_duration"`
+}
+
+type TagsResponse struct {
+	Models []struct {
+		Name    string `json:"name"`
+		Details struct {
+			ParameterSize string `json:"parameter_size"`
+			// Families includes an image encoder such as "clip" or
+			// "mllama" for vision models
+			Families []string `json:"families"`
+		} `json:"details"`
+	} `json:"models"`
+}
@@ -0,0 +1,29 @@
+package provider
+
+import "strings"
+
+// cheapMarkers are parts of the IDs of the small, low-priced tiers of model
+// families, cheapest first.
+var cheapMarkers = []string{
+	"-nano", "flash-lite", "-mini", "haiku", "instant", "flash", "-small",
+}
+
+// CheapestModel guesses from their IDs which of models costs the least to
+// run, for APIs whose model list has no prices. It reports false when none
+// of the available models is known to belong to a low-priced tier.
+func CheapestModel(models []Model) (string, bool) {
+	cheapest, rank := "", len(cheapMarkers)
+	for _, model := range models {
+		if model.Unavailable {
+			continue
+		}
+		id := strings.ToLower(model.ID)
+		for i, marker := range cheapMarkers[:rank] {
+			if strings.Contains(id, marker) {
+				cheapest, rank = model.ID, i
+				break
+			}
+		}
+	}
+	return cheapest, cheapest != ""
+}
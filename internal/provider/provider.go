@@ -0,0 +1,164 @@
+// Package provider defines the interface every chat backend implements,
+// so the UI can talk to Groq or any other vendor the same way.
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+)
+
+// Model describes a model offered by a provider.
+type Model struct {
+	ID   string
+	Name string
+	// Unavailable marks models that were offered before, or were named by
+	// the user, but are not served anymore.
+	Unavailable bool
+	// ContextWindow is the number of tokens the model accepts per request,
+	// or zero when unknown.
+	ContextWindow int
+	// Vision reports that the model accepts image attachments.
+	Vision bool
+}
+
+// SameModel reports whether id names the listed model. Besides the exact
+// ID it accepts the names providers take in requests but list differently:
+// an Ollama model without its ":latest" tag, and an alias such as
+// "claude-sonnet-4-5" for the dated snapshot "claude-sonnet-4-5-20250929".
+func SameModel(listed, id string) bool {
+	if listed == id {
+		return true
+	}
+	if strings.TrimSuffix(listed, ":latest") == strings.TrimSuffix(id, ":latest") {
+		return true
+	}
+	date, ok := strings.CutPrefix(listed, id+"-")
+	return ok && len(date) == 8 && strings.Trim(date, "0123456789") == ""
+}
+
+// Capabilities lists the optional features a provider supports.
+type Capabilities struct {
+	Streaming bool
+	// Tools reports support for tool calling: the provider offers
+	// ChatRequest.Tools to the model and returns its calls.
+	Tools bool
+	// Vision reports support for image attachments: the provider sends
+	// the images attached to messages to models that accept them.
+	Vision bool
+}
+
+// ChatRequest is a provider-neutral chat completion request. A system
+// prompt, if any, is the first message with role "system".
+type ChatRequest struct {
+	Model    string
+	Messages []storage.ChatMessage
+	Params   storage.GenerationParams
+	Tools    []ToolSpec
+	// JSON asks the model to reply with a single JSON object. The messages
+	// should say so too, as some providers require it.
+	JSON bool
+}
+
+// ToolSpec describes a tool the model may call. Parameters is a JSON Schema
+// object describing its arguments.
+type ToolSpec struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage
+}
+
+// ChatResponse is a provider-neutral chat completion result.
+type ChatResponse struct {
+	Content string
+	// Reasoning is the thinking of a reasoning model, when the provider
+	// returns it apart from Content. Others leave it in <think> tags.
+	Reasoning string
+	// ToolCalls holds the tools the model wants run before it answers.
+	ToolCalls []storage.ToolCall
+	// Usage is nil when the provider did not report token counts.
+	Usage *storage.Usage
+	// FinishReason says why the model stopped, such as FinishStop or
+	// FinishLength, and is empty when the provider did not say.
+	FinishReason string
+}
+
+// Finish reasons shared by every provider. Others are passed through as
+// the provider reports them.
+const (
+	FinishStop = "stop"
+	// FinishLength means the reply was cut off by max_tokens
+	FinishLength = "length"
+)
+
+// Provider is a chat backend.
+type Provider interface {
+	// Name returns the identifier stored on sessions, e.g. "groq".
+	Name() string
+	// DefaultModel returns the model used when a session has none set.
+	DefaultModel() string
+	// Configured reports whether the provider has what it needs, such as
+	// an API key, to send requests.
+	Configured() bool
+	// ListModels returns the models that can be selected.
+	ListModels(ctx context.Context) ([]Model, error)
+	// Chat sends the conversation and waits for the complete reply.
+	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+	// Stream sends the conversation and calls onDelta for each chunk of
+	// text as it arrives. On cancellation the partial reply is returned
+	// together with the context's error.
+	Stream(ctx context.Context, req ChatRequest, onDelta func(string)) (*ChatResponse, error)
+	// Capabilities reports the optional features the provider supports.
+	Capabilities() Capabilities
+}
+
+// RateLimit is the quota a provider reported on its latest response.
+// Counts the provider did not report are zero.
+type RateLimit struct {
+	LimitRequests     int
+	RemainingRequests int
+	ResetRequests     time.Duration
+	LimitTokens       int
+	RemainingTokens   int
+	ResetTokens       time.Duration
+	UpdatedAt         time.Time
+}
+
+// RateLimited is implemented by providers that report their remaining
+// quota.
+type RateLimited interface {
+	RateLimit() (RateLimit, bool)
+}
+
+// TranscriptionRequest asks for the speech in an audio file as text.
+type TranscriptionRequest struct {
+	// FileName tells the audio format by its extension, e.g. "memo.m4a"
+	FileName string
+	Audio    []byte
+	// Model is the speech model to use, or empty for the provider's default
+	Model string
+	// Language is the ISO-639-1 code of the spoken language, e.g. "de".
+	// Empty lets the model detect it.
+	Language string
+}
+
+// Transcription is the text of a transcribed audio file.
+type Transcription struct {
+	Text  string
+	Model string
+}
+
+// Transcriber is implemented by providers that can transcribe speech.
+type Transcriber interface {
+	Transcribe(ctx context.Context, req TranscriptionRequest) (*Transcription, error)
+}
+
+// HintedError is implemented by errors that can tell the user how to fix
+// them, such as an API error for a rejected key.
+type HintedError interface {
+	error
+	Hint() string
+}
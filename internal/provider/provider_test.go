@@ -0,0 +1,74 @@
+package provider_test
+
+import (
+	"testing"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+)
+
+func TestSameModel(t *testing.T) {
+	tests := []struct {
+		listed, id string
+		want       bool
+	}{
+		{"llama-3.3-70b-versatile", "llama-3.3-70b-versatile", true},
+		{"llama3.2:latest", "llama3.2", true},
+		{"llama3.2", "llama3.2:latest", true},
+		{"claude-sonnet-4-5-20250929", "claude-sonnet-4-5", true},
+		{"claude-sonnet-4-20250514", "claude-sonnet-4", true},
+		{"llama3.2:1b", "llama3.2", false},
+		{"claude-sonnet-4-5-20250929", "claude-sonnet-4", false},
+		{"gpt-4-turbo", "gpt-4", false},
+		{"gemini-2.0-flash-lite", "gemini-2.0-flash", false},
+	}
+	for _, tt := range tests {
+		if got := provider.SameModel(tt.listed, tt.id); got != tt.want {
+			t.Errorf("SameModel(%q, %q) = %v, want %v", tt.listed, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestCheapestModel(t *testing.T) {
+	tests := []struct {
+		name   string
+		models []provider.Model
+		want   string
+	}{
+		{
+			name:   "groq",
+			models: []provider.Model{{ID: "llama-3.3-70b-versatile"}, {ID: "llama-3.1-8b-instant"}, {ID: "gemma2-9b-it"}},
+			want:   "llama-3.1-8b-instant",
+		},
+		{
+			name:   "anthropic",
+			models: []provider.Model{{ID: "claude-sonnet-4-5"}, {ID: "claude-haiku-4-5"}, {ID: "claude-opus-4-1"}},
+			want:   "claude-haiku-4-5",
+		},
+		{
+			name:   "gemini",
+			models: []provider.Model{{ID: "gemini-2.5-pro"}, {ID: "gemini-2.5-flash"}, {ID: "gemini-2.0-flash-lite"}},
+			want:   "gemini-2.0-flash-lite",
+		},
+		{
+			name:   "openai",
+			models: []provider.Model{{ID: "gpt-4.1"}, {ID: "gpt-4.1-mini"}, {ID: "gpt-4.1-nano"}},
+			want:   "gpt-4.1-nano",
+		},
+		{
+			name:   "unavailable models are skipped",
+			models: []provider.Model{{ID: "claude-3-haiku", Unavailable: true}, {ID: "claude-sonnet-4-5"}},
+		},
+		{
+			name:   "no known tier",
+			models: []provider.Model{{ID: "llama3.2"}, {ID: "qwen2.5-coder"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := provider.CheapestModel(tt.models)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("CheapestModel = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
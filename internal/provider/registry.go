@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{}
+	order     []string
+)
+
+// Register makes a provider available by its name. Registering a second
+// provider with the same name replaces the first.
+func Register(p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, exists := providers[p.Name()]; !exists {
+		order = append(order, p.Name())
+	}
+	providers[p.Name()] = p
+}
+
+// Get returns the provider registered under name.
+func Get(name string) (Provider, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	p, exists := providers[name]
+	if !exists {
+		return nil, fmt.Errorf("provider %s not registered", name)
+	}
+	return p, nil
+}
+
+// All returns the registered providers in registration order.
+func All() []Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	all := make([]Provider, 0, len(order))
+	for _, name := range order {
+		all = append(all, providers[name])
+	}
+	return all
+}
+
+// Default returns the first registered provider that is configured, so
+// keyless providers are picked when no API key is set. If none is
+// configured the first registered provider is returned.
+func Default() Provider {
+	all := All()
+	for _, p := range all {
+		if p.Configured() {
+			return p
+		}
+	}
+	if len(all) > 0 {
+		return all[0]
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package provider
+
+import "strings"
+
+// visionMarkers are parts of the IDs of model families known to accept
+// images.
+var visionMarkers = []string{
+	"vision", "llama-4", "llama4", "llava", "-vl", "vl-", "pixtral",
+	"gemma-3", "gemma3", "minicpm-v", "moondream", "gpt-4o", "gpt-4.1",
+	"claude", "gemini",
+}
+
+// IsVisionModel guesses from a model ID whether the model accepts images,
+// for APIs whose model list does not say.
+func IsVisionModel(id string) bool {
+	id = strings.ToLower(id)
+	for _, marker := range visionMarkers {
+		if strings.Contains(id, marker) {
+			return true
+		}
+	}
+	return false
+}
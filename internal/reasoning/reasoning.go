@@ -0,0 +1,111 @@
+// Package reasoning separates what reasoning models think out loud from
+// their answers, so the thinking can be shown apart and left out of the
+// history sent on later turns.
+package reasoning
+
+import "strings"
+
+const (
+	openTag  = "<think>"
+	closeTag = "</think>"
+
+	// nearStart is how far into a reply a closing tag without an opening
+	// one may come and still end thinking, for models not known to leave
+	// the opening tag out. Further on it is taken as part of the answer.
+	nearStart = 200
+)
+
+// unopenedMarkers are parts of the IDs of models whose chat templates put
+// the opening tag into the prompt, so their replies start mid-thought and
+// only the closing tag shows up.
+var unopenedMarkers = []string{"deepseek-r1", "r1-distill", "qwq"}
+
+// Split separates the thinking at the start of content from the answer
+// after it. Only a <think> block opening the reply counts, as models put
+// their reasoning first; tags further on are part of the answer, such as a
+// reply explaining the tag itself. A block still open, as seen mid-stream,
+// runs to the end of the content. A closing tag without an opening one
+// ends thinking that began with the reply only when it comes near the
+// start, as some servers strip the opening tag; SplitFor also accepts it
+// further on from models known to leave the tag out. Tags inside code are
+// ignored. Content without leading thinking is returned unchanged.
+func Split(content string) (answer, thinking string) {
+	return split(content, nearStart)
+}
+
+// SplitFor is Split for a reply written by model.
+func SplitFor(model, content string) (answer, thinking string) {
+	if omitsOpenTag(model) {
+		return split(content, len(content))
+	}
+	return split(content, nearStart)
+}
+
+// omitsOpenTag reports whether model is known to reply without the opening
+// tag of its thinking.
+func omitsOpenTag(model string) bool {
+	model = strings.ToLower(model)
+	for _, marker := range unopenedMarkers {
+		if strings.Contains(model, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// split is Split, accepting a closing tag without an opening one up to
+// unopened bytes into content.
+func split(content string, unopened int) (answer, thinking string) {
+	rest := strings.TrimLeft(content, " \t\r\n")
+	if !strings.HasPrefix(rest, openTag) {
+		end := closing(rest)
+		if end < 0 || end > unopened || strings.Contains(rest[:end], openTag) {
+			return content, ""
+		}
+		return Join(rest[end+len(closeTag):]), Join(rest[:end])
+	}
+
+	var thoughts []string
+	for strings.HasPrefix(rest, openTag) {
+		rest = rest[len(openTag):]
+		end := closing(rest)
+		if end < 0 {
+			thoughts = append(thoughts, rest)
+			rest = ""
+			break
+		}
+		thoughts = append(thoughts, rest[:end])
+		rest = strings.TrimLeft(rest[end+len(closeTag):], " \t\r\n")
+	}
+	return Join(rest), Join(thoughts...)
+}
+
+// closing returns the index of the first closing tag in s that is not
+// inside inline or fenced code, judged by an odd number of backticks
+// before it, or -1 if there is none.
+func closing(s string) int {
+	offset := 0
+	for {
+		end := strings.Index(s[offset:], closeTag)
+		if end < 0 {
+			return -1
+		}
+		end += offset
+		if strings.Count(s[:end], "`")%2 == 0 {
+			return end
+		}
+		offset = end + len(closeTag)
+	}
+}
+
+// Join puts pieces of text together, one paragraph each, skipping empty
+// ones.
+func Join(parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
@@ -0,0 +1,105 @@
+package reasoning_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/reasoning"
+)
+
+func TestSplit(t *testing.T) {
+	long := strings.Repeat("Let me weigh every option once more. ", 20)
+	tests := []struct {
+		name     string
+		content  string
+		answer   string
+		thinking string
+	}{
+		{
+			name:    "no thinking",
+			content: "Paris is the capital of France.",
+			answer:  "Paris is the capital of France.",
+		},
+		{
+			name:     "leading block",
+			content:  "<think>\nThe user asks about France.\n</think>\n\nParis.",
+			answer:   "Paris.",
+			thinking: "The user asks about France.",
+		},
+		{
+			name:     "consecutive blocks",
+			content:  "<think>First.</think>\n<think>Second.</think>\nParis.",
+			answer:   "Paris.",
+			thinking: "First.\n\nSecond.",
+		},
+		{
+			name:     "block still open",
+			content:  "<think>Still weighing",
+			thinking: "Still weighing",
+		},
+		{
+			name:    "tag later in the answer",
+			content: "Models wrap thoughts like <think>this</think>.",
+			answer:  "Models wrap thoughts like <think>this</think>.",
+		},
+		{
+			name:     "closing tag near the start",
+			content:  "The user wants a capital.</think>\nParis.",
+			answer:   "Paris.",
+			thinking: "The user wants a capital.",
+		},
+		{
+			name:    "stray closing tag far into the answer",
+			content: long + "Close a block with </think> when done.",
+			answer:  long + "Close a block with </think> when done.",
+		},
+		{
+			name:    "closing tag in code",
+			content: "Close it with `</think>`.",
+			answer:  "Close it with `</think>`.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer, thinking := reasoning.Split(tt.content)
+			if answer != tt.answer || thinking != tt.thinking {
+				t.Errorf("Split = %q, %q, want %q, %q", answer, thinking, tt.answer, tt.thinking)
+			}
+		})
+	}
+}
+
+func TestSplitFor(t *testing.T) {
+	long := strings.Repeat("Let me weigh every option once more. ", 20)
+	content := long + "</think>\n\nParis."
+
+	answer, thinking := reasoning.SplitFor("deepseek-r1-distill-llama-70b", content)
+	if answer != "Paris." || thinking != strings.TrimSpace(long) {
+		t.Errorf("SplitFor(deepseek-r1) = %q, %q, want the thinking apart", answer, thinking)
+	}
+
+	answer, thinking = reasoning.SplitFor("llama-3.3-70b-versatile", content)
+	if answer != content || thinking != "" {
+		t.Errorf("SplitFor(llama) = %q, %q, want the content unchanged", answer, thinking)
+	}
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []string
+		want  string
+	}{
+		{"nothing", nil, ""},
+		{"one part", []string{" First. \n"}, "First."},
+		{"paragraphs", []string{"First.", "Second."}, "First.\n\nSecond."},
+		{"empty parts skipped", []string{"", "First.", " \n ", "Second."}, "First.\n\nSecond."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reasoning.Join(tt.parts...); got != tt.want {
+				t.Errorf("Join(%q) = %q, want %q", tt.parts, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package storage
+
+// Versions returns every version of a regenerated reply in the order they
+// were generated, the shown one included. Replies that were never
+// regenerated have a single version.
+func (m ChatMessage) Versions() []ChatMessage {
+	shown := m
+	shown.Alternatives = nil
+	shown.AltIndex = 0
+
+	index := min(m.AltIndex, len(m.Alternatives))
+	versions := make([]ChatMessage, 0, len(m.Alternatives)+1)
+	versions = append(versions, m.Alternatives[:index]...)
+	versions = append(versions, shown)
+	versions = append(versions, m.Alternatives[index:]...)
+	return versions
+}
+
+// WithVersion returns the reply showing version i instead, keeping the
+// others as its alternatives.
+func (m ChatMessage) WithVersion(i int) ChatMessage {
+	return withVersion(m.Versions(), i)
+}
+
+// AddVersion returns the reply showing reply as its newest version, with
+// the current version and its alternatives kept alongside.
+func (m ChatMessage) AddVersion(reply ChatMessage) ChatMessage {
+	// Every version stands in the same place in the chat
+	reply.ID, reply.ParentID = m.ID, m.ParentID
+	versions := append(m.Versions(), reply.Versions()...)
+	return withVersion(versions, len(versions)-1)
+}
+
+// TotalUsage sums the usage of every version of the reply.
+func (m ChatMessage) TotalUsage() Usage {
+	var total Usage
+	for _, version := range m.Versions() {
+		if version.Usage != nil {
+			total.Add(*version.Usage)
+		}
+	}
+	return total
+}
+
+func withVersion(versions []ChatMessage, i int) ChatMessage {
+	chosen := versions[i]
+	chosen.Alternatives = append(versions[:i:i], versions[i+1:]...)
+	chosen.AltIndex = i
+	if len(chosen.Alternatives) == 0 {
+		chosen.Alternatives = nil
+	}
+	return chosen
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxImageSize is the largest image that can be attached.
+const MaxImageSize = 20 << 20
+
+// Attachment is a file sent along with a message. Its contents are saved
+// with the chat, so the message can be sent again after the file is gone.
+type Attachment struct {
+	Name     string `json:"name"`
+	MIMEType string `json:"mime_type"`
+	Data     []byte `json:"data"`
+}
+
+// Transcription records that a message was dictated: the audio file it
+// was transcribed from, and the transcript as the model returned it before
+// any edits. Only the message's content is sent.
+type Transcription struct {
+	Source string `json:"source"`
+	Model  string `json:"model,omitempty"`
+	Text   string `json:"text"`
+}
+
+// LoadImage reads the image at path into an attachment. PNG, JPEG, GIF and
+// WebP images are accepted.
+func LoadImage(path string) (Attachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read image: %v", err)
+	}
+	if info.Size() > MaxImageSize {
+		return Attachment{}, fmt.Errorf("%s is larger than %dMB", filepath.Base(path), MaxImageSize>>20)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read image: %v", err)
+	}
+
+	mimeType := http.DetectContentType(data)
+	switch mimeType {
+	case "image/png", "image/jpeg", "image/gif", "image/webp":
+	default:
+		return Attachment{}, fmt.Errorf("%s is not a PNG, JPEG, GIF or WebP image", filepath.Base(path))
+	}
+
+	return Attachment{Name: filepath.Base(path), MIMEType: mimeType, Data: data}, nil
+}
+
+// IsImage reports whether the attachment is an image.
+func (a Attachment) IsImage() bool {
+	return strings.HasPrefix(a.MIMEType, "image/")
+}
+
+// Base64 returns the attachment's contents in standard base64.
+func (a Attachment) Base64() string {
+	return base64.StdEncoding.EncodeToString(a.Data)
+}
+
+// DataURL returns the attachment as a data: URL, the way OpenAI-style APIs
+// take inline images.
+func (a Attachment) DataURL() string {
+	return "data:" + a.MIMEType + ";base64," + a.Base64()
+}
+
+// Images returns the image attachments of the message.
+func (m ChatMessage) Images() []Attachment {
+	var images []Attachment
+	for _, attachment := range m.Attachments {
+		if attachment.IsImage() {
+			images = append(images, attachment)
+		}
+	}
+	return images
+}
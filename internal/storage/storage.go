@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -19,17 +20,158 @@ const (
 )
 
 type ChatMessage struct {
+	// ID identifies the message within its chat, and ParentID is the
+	// message it follows, so that edited messages can branch off
+	ID        string    `json:"id,omitempty"`
+	ParentID  string    `json:"parent_id,omitempty"`
 	Role      string    `json:"role"`
 	Content   string    `json:"content"`
 	Timestamp time.Time `json:"timestamp"`
+	Cancelled bool      `json:"cancelled,omitempty"`
+	Hint      string    `json:"hint,omitempty"`
+	// Pinned messages are kept when the history is trimmed to fit the
+	// model's context window
+	Pinned bool `json:"pinned,omitempty"`
+
+	// Tools the assistant asked to run, and on "tool" messages the call
+	// the result answers
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+
+	// Files sent along with a user message, such as images, and the
+	// recording the message was transcribed from
+	Attachments   []Attachment   `json:"attachments,omitempty"`
+	Transcription *Transcription `json:"transcription,omitempty"`
+
+	// Set on assistant replies. Reasoning is what a reasoning model
+	// thought before answering; it is shown but never sent back.
+	Reasoning string            `json:"reasoning,omitempty"`
+	Model     string            `json:"model,omitempty"`
+	Usage     *Usage            `json:"usage,omitempty"`
+	LatencyMs int64             `json:"latency_ms,omitempty"`
+	Params    *GenerationParams `json:"params,omitempty"`
+	Context   *ContextStats     `json:"context,omitempty"`
+	// Why the model stopped, "length" when max_tokens cut it off, and how
+	// many follow-up requests were merged into the reply
+	FinishReason string `json:"finish_reason,omitempty"`
+	Continued    int    `json:"continued,omitempty"`
+
+	// Other versions of a regenerated reply, in the order they were
+	// generated. AltIndex is this version's place among them.
+	Alternatives []ChatMessage `json:"alternatives,omitempty"`
+	AltIndex     int           `json:"alt_index,omitempty"`
+}
+
+// ToolCall is a model's request to run a local tool. Arguments is the JSON
+// object the model passed.
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// ContextStats records how much of the history was sent with a request
+// that had to be trimmed to fit the context window.
+type ContextStats struct {
+	Sent       int    `json:"sent"`
+	Total      int    `json:"total"`
+	Strategy   string `json:"strategy"`
+	Summarized int    `json:"summarized,omitempty"`
+}
+
+// ContextSummary is a running summary of the oldest messages of a chat,
+// sent in their place once they no longer fit the context window.
+type ContextSummary struct {
+	Content string `json:"content"`
+	// Covers is how many of the chat's sendable messages it summarizes
+	Covers int `json:"covers"`
+}
+
+// GenerationParams are optional sampling settings. Nil fields are left to
+// the provider's defaults.
+type GenerationParams struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+}
+
+// IsZero reports whether no parameter is set.
+func (p GenerationParams) IsZero() bool {
+	return p.Temperature == nil && p.TopP == nil && p.MaxTokens == nil && len(p.Stop) == 0 && p.Seed == nil
+}
+
+// Merge returns p with every parameter set in override replacing its own.
+func (p GenerationParams) Merge(override GenerationParams) GenerationParams {
+	if override.Temperature != nil {
+		p.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		p.TopP = override.TopP
+	}
+	if override.MaxTokens != nil {
+		p.MaxTokens = override.MaxTokens
+	}
+	if len(override.Stop) > 0 {
+		p.Stop = override.Stop
+	}
+	if override.Seed != nil {
+		p.Seed = override.Seed
+	}
+	return p
+}
+
+// Usage is the token accounting a provider reported for one reply. Timings
+// are in seconds as reported by the provider, and zero when unknown.
+type Usage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	QueueTime        float64 `json:"queue_time,omitempty"`
+	TotalTime        float64 `json:"total_time,omitempty"`
+}
+
+// Add accumulates other into u.
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.QueueTime += other.QueueTime
+	u.TotalTime += other.TotalTime
 }
 
 type ChatSession struct {
-	ID        string        `json:"id"`
-	Title     string        `json:"title"`
-	CreatedAt time.Time     `json:"created_at"`
-	UpdatedAt time.Time     `json:"updated_at"`
-	Messages  []ChatMessage `json:"messages"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Provider is the name of the provider or endpoint profile the chat
+	// talks to
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+	// Description is a model-written summary of the chat. AutoTitled is set
+	// once the title and description have been generated.
+	Description string `json:"description,omitempty"`
+	AutoTitled  bool   `json:"auto_titled,omitempty"`
+	// Copied from the persona the chat was started with, so reopening the
+	// chat behaves the same even if the persona changes later
+	Persona      string            `json:"persona,omitempty"`
+	SystemPrompt string            `json:"system_prompt,omitempty"`
+	Params       *GenerationParams `json:"params,omitempty"`
+	// How to trim the history when it outgrows the context window, and the
+	// summary kept by the "summarize" strategy
+	ContextStrategy string          `json:"context_strategy,omitempty"`
+	Summary         *ContextSummary `json:"summary,omitempty"`
+	// Tools lets the model call the built-in tools
+	Tools bool `json:"tools,omitempty"`
+	// JSONMode asks for JSON replies, checked against JSONSchema if set
+	JSONMode   bool            `json:"json_mode,omitempty"`
+	JSONSchema json.RawMessage `json:"json_schema,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	// Messages is the active path through the chat, and Branches the
+	// messages of the paths left behind by editing earlier messages
+	Messages []ChatMessage `json:"messages"`
+	Branches []ChatMessage `json:"branches,omitempty"`
 }
 
 type Storage struct {
@@ -47,24 +189,7 @@ func (s *Storage) Initialize() error {
 }
 
 func (s *Storage) SaveChat(session *ChatSession) error {
-	if session.ID == "" {
-		session.ID = generateChatID()
-	}
-
-	session.UpdatedAt = time.Now()
-
-	// Generate title from first user message if not set
-	if session.Title == "" && len(session.Messages) > 0 {
-		for _, msg := range session.Messages {
-			if msg.Role == "user" {
-				session.Title = generateTitle(msg.Content)
-				break
-			}
-		}
-		if session.Title == "" {
-			session.Title = "New Chat"
-		}
-	}
+	session.stamp()
 
 	filename := fmt.Sprintf("%s.json", session.ID)
 	filepath := filepath.Join(s.baseDir, filename)
@@ -77,6 +202,48 @@ func (s *Storage) SaveChat(session *ChatSession) error {
 	return os.WriteFile(filepath, data, 0644)
 }
 
+// Snapshot stamps the session as saved now and returns a copy of it that
+// SaveChat can write on another goroutine while this one goes on changing
+// the session.
+func (s *ChatSession) Snapshot() *ChatSession {
+	s.stamp()
+	snapshot := *s
+	snapshot.Messages = slices.Clone(s.Messages)
+	snapshot.Branches = slices.Clone(s.Branches)
+	if s.Summary != nil {
+		summary := *s.Summary
+		snapshot.Summary = &summary
+	}
+	if s.Params != nil {
+		params := *s.Params
+		snapshot.Params = &params
+	}
+	return &snapshot
+}
+
+// stamp gives the session its ID, its update time and, when it has none,
+// a title taken from the first user message.
+func (s *ChatSession) stamp() {
+	if s.ID == "" {
+		s.ID = generateChatID()
+	}
+
+	s.UpdatedAt = time.Now()
+
+	// Generate title from first user message if not set
+	if s.Title == "" && len(s.Messages) > 0 {
+		for _, msg := range s.Messages {
+			if msg.Role == "user" {
+				s.Title = generateTitle(msg.Content)
+				break
+			}
+		}
+		if s.Title == "" {
+			s.Title = "New Chat"
+		}
+	}
+}
+
 func (s *Storage) LoadChat(chatID string) (*ChatSession, error) {
 	filename := fmt.Sprintf("%s.json", chatID)
 	filepath := filepath.Join(s.baseDir, filename)
@@ -146,6 +313,7 @@ func (s *Storage) GetChatSummaries() ([]ChatSummary, error) {
 		summary := ChatSummary{
 			ID:           session.ID,
 			Title:        session.Title,
+			Description:  session.Description,
 			CreatedAt:    session.CreatedAt,
 			UpdatedAt:    session.UpdatedAt,
 			MessageCount: len(session.Messages),
@@ -156,9 +324,23 @@ func (s *Storage) GetChatSummaries() ([]ChatSummary, error) {
 	return summaries, nil
 }
 
+// SessionUsage totals the token usage of every reply in a session,
+// regenerated versions and other branches included.
+func SessionUsage(session *ChatSession) Usage {
+	var total Usage
+	for _, msg := range session.Messages {
+		total.Add(msg.TotalUsage())
+	}
+	for _, msg := range session.Branches {
+		total.Add(msg.TotalUsage())
+	}
+	return total
+}
+
 type ChatSummary struct {
 	ID           string    `json:"id"`
 	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	MessageCount int       `json:"message_count"`
@@ -179,8 +361,9 @@ func generateTitle(content string) string {
 		title = strings.ReplaceAll(title, "  ", " ")
 	}
 
-	if len(title) > maxChatName {
-		title = title[:maxChatName] + "..."
+	// Cut on a rune boundary so multibyte characters stay whole
+	if runes := []rune(title); len(runes) > maxChatName {
+		title = string(runes[:maxChatName]) + "..."
 	}
 
 	if title == "" {
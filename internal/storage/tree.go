@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+)
+
+// A chat is a tree of messages: editing an earlier message starts a new
+// branch next to the original one. Messages holds the active path from the
+// first message to the latest, and Branches holds every message of the
+// other paths. Each message points at the one before it through ParentID.
+
+func newMessageID() string {
+	return fmt.Sprintf("msg_%016x", rand.Uint64())
+}
+
+// Link gives every message on the active path that has none an ID and
+// points each one at the message before it. It changes the messages in
+// place, so call it on the goroutine that owns the session, before the
+// session is saved.
+func (s *ChatSession) Link() {
+	parent := ""
+	for i := range s.Messages {
+		if s.Messages[i].ID == "" {
+			s.Messages[i].ID = newMessageID()
+		}
+		s.Messages[i].ParentID = parent
+		parent = s.Messages[i].ID
+	}
+}
+
+// Siblings returns the messages that could take the place of message i of
+// the active path, oldest first, and the position of message i among them.
+// A message that was never edited is its own only sibling.
+func (s *ChatSession) Siblings(i int) ([]ChatMessage, int) {
+	s.Link()
+	return Siblings(s.Messages[i], s.Branches)
+}
+
+// Siblings returns msg and the branch messages sharing its parent, oldest
+// first, and the position of msg among them. Messages not yet linked have
+// no siblings.
+func Siblings(msg ChatMessage, branches []ChatMessage) ([]ChatMessage, int) {
+	siblings := []ChatMessage{msg}
+	if msg.ID == "" {
+		return siblings, 0
+	}
+	for _, branch := range branches {
+		if branch.ParentID == msg.ParentID {
+			siblings = append(siblings, branch)
+		}
+	}
+	sort.SliceStable(siblings, func(a, b int) bool {
+		return siblings[a].Timestamp.Before(siblings[b].Timestamp)
+	})
+	for position, sibling := range siblings {
+		if sibling.ID == msg.ID {
+			return siblings, position
+		}
+	}
+	return siblings, 0
+}
+
+// Fork moves message i of the active path and everything after it into
+// the branches, so that a new message can be appended in its place.
+func (s *ChatSession) Fork(i int) {
+	s.Link()
+	s.Branches = append(s.Branches, s.Messages[i:]...)
+	s.Messages = s.Messages[:i:i]
+}
+
+// SwitchBranch makes the branch starting with the sibling id of message i
+// the active path, following its most recent messages to the end. The
+// path it replaces is kept in the branches.
+func (s *ChatSession) SwitchBranch(i int, id string) error {
+	s.Link()
+	if s.Messages[i].ID == id {
+		return nil
+	}
+
+	byParent := map[string][]ChatMessage{}
+	var next *ChatMessage
+	for _, msg := range s.Branches {
+		byParent[msg.ParentID] = append(byParent[msg.ParentID], msg)
+		if msg.ID == id {
+			next = &msg
+		}
+	}
+	if next == nil || next.ParentID != s.Messages[i].ParentID {
+		return fmt.Errorf("no branch %s at message %d", id, i+1)
+	}
+
+	path := append(s.Messages[:i:i], *next)
+	for {
+		children := byParent[path[len(path)-1].ID]
+		if len(children) == 0 {
+			break
+		}
+		latest := children[0]
+		for _, child := range children[1:] {
+			if child.Timestamp.After(latest.Timestamp) {
+				latest = child
+			}
+		}
+		path = append(path, latest)
+	}
+
+	onPath := map[string]bool{}
+	for _, msg := range path[i:] {
+		onPath[msg.ID] = true
+	}
+	branches := append([]ChatMessage{}, s.Messages[i:]...)
+	for _, msg := range s.Branches {
+		if !onPath[msg.ID] {
+			branches = append(branches, msg)
+		}
+	}
+
+	s.Messages = path
+	s.Branches = branches
+	return nil
+}
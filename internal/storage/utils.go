@@ -183,6 +183,7 @@ func (s *Storage) ImportChatFromText(filePath string, title string) (*ChatSessio
 	}
 
 	// Save the imported session
+	session.Link()
 	if err := s.SaveChat(session); err != nil {
 		return nil, fmt.Errorf("failed to save imported chat: %v", err)
 	}
@@ -249,6 +250,26 @@ func (s *Storage) ValidateStorage() ([]string, error) {
 	return issues, nil
 }
 
+// TotalUsage sums the token usage of every saved chat except the one with
+// excludeID, which lets callers add the live copy of that chat themselves.
+func (s *Storage) TotalUsage(excludeID string) (Usage, error) {
+	var total Usage
+
+	sessions, err := s.ListChats()
+	if err != nil {
+		return total, err
+	}
+
+	for i := range sessions {
+		if sessions[i].ID == excludeID {
+			continue
+		}
+		total.Add(SessionUsage(&sessions[i]))
+	}
+
+	return total, nil
+}
+
 // GetChatsByDateRange returns chats within a specific date range
 func (s *Storage) GetChatsByDateRange(startDate, endDate time.Time) ([]ChatSession, error) {
 	allSessions, err := s.ListChats()
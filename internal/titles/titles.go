@@ -0,0 +1,87 @@
+// Package titles asks a model for a short title and summary of a chat, to
+// show in the chat list instead of the start of the first message.
+package titles
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/jsonmode"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+)
+
+const (
+	maxTokens = 300
+	// maxTitle caps the title in runes, in case the model ignores the prompt
+	maxTitle = 60
+	// messageLimit caps the runes sent of each message, so describing a chat
+	// that opens with a long paste stays cheap
+	messageLimit = 2000
+
+	prompt = "Describe the conversation below for a list of saved chats. " +
+		"Reply with only a JSON object with two string fields: \"title\", a title of at most six words " +
+		"without quotes or a trailing period, and \"summary\", one paragraph of at most 60 words " +
+		"saying what was asked and answered."
+)
+
+// Description is a chat's generated title and summary.
+type Description struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+}
+
+// Generate asks model to describe the sendable messages of a chat.
+func Generate(ctx context.Context, p provider.Provider, model string, messages []storage.ChatMessage) (Description, error) {
+	var transcript strings.Builder
+	for _, msg := range messages {
+		switch msg.Role {
+		case "user":
+			transcript.WriteString("User: ")
+		case "assistant":
+			transcript.WriteString("Assistant: ")
+		default:
+			continue
+		}
+		transcript.WriteString(truncate(msg.Content, messageLimit) + "\n\n")
+	}
+	if transcript.Len() == 0 {
+		return Description{}, fmt.Errorf("nothing to describe")
+	}
+
+	limit := maxTokens
+	temperature := 0.3
+	resp, err := p.Chat(ctx, provider.ChatRequest{
+		Model: model,
+		Messages: []storage.ChatMessage{
+			{Role: "system", Content: prompt},
+			{Role: "user", Content: transcript.String()},
+		},
+		Params: storage.GenerationParams{MaxTokens: &limit, Temperature: &temperature},
+		JSON:   true,
+	})
+	if err != nil {
+		return Description{}, fmt.Errorf("failed to describe chat: %v", err)
+	}
+
+	var description Description
+	if err := json.Unmarshal([]byte(jsonmode.Extract(resp.Content)), &description); err != nil {
+		return Description{}, fmt.Errorf("failed to describe chat: %v", err)
+	}
+	description.Title = truncate(strings.Trim(strings.TrimSpace(description.Title), `"'.`), maxTitle)
+	description.Summary = strings.TrimSpace(description.Summary)
+	if description.Title == "" {
+		return Description{}, fmt.Errorf("failed to describe chat: empty title")
+	}
+	return description, nil
+}
+
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "..."
+}
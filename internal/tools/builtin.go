@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// maxFileBytes caps how much of a file read_file returns.
+const maxFileBytes = 64 * 1024
+
+// Builtin returns a registry of the built-in tools: a calculator, the
+// current time and, when TUI_GPT_FILES_DIR names a directory, read_file
+// for files inside it.
+func Builtin() *Registry {
+	r := NewRegistry(calculator(), currentTime())
+	if dir := os.Getenv("TUI_GPT_FILES_DIR"); dir != "" {
+		r.Register(readFile(dir))
+	}
+	return r
+}
+
+func calculator() Tool {
+	return Tool{
+		Name:        "calculator",
+		Description: "Evaluate an arithmetic expression. Supports + - * / %, parentheses, pi, e, the functions sqrt, abs, ln, log10, sin, cos, tan, floor, ceil and round, and pow(x, y), min(x, y) and max(x, y).",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"expression": {"type": "string", "description": "The expression to evaluate, e.g. pow(2 + 3, 2) / 7"}
+			},
+			"required": ["expression"]
+		}`),
+		Run: func(ctx context.Context, raw json.RawMessage) (string, error) {
+			var args struct {
+				Expression string `json:"expression"`
+			}
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return "", fmt.Errorf("invalid arguments: %v", err)
+			}
+			value, err := Evaluate(args.Expression)
+			if err != nil {
+				return "", err
+			}
+			return strconv.FormatFloat(value, 'f', -1, 64), nil
+		},
+	}
+}
+
+func currentTime() Tool {
+	return Tool{
+		Name:        "current_time",
+		Description: "Get the current date and time, in the local time zone or a given IANA time zone.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"timezone": {"type": "string", "description": "IANA time zone such as Europe/Berlin. Defaults to local time."}
+			}
+		}`),
+		Run: func(ctx context.Context, raw json.RawMessage) (string, error) {
+			var args struct {
+				Timezone string `json:"timezone"`
+			}
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return "", fmt.Errorf("invalid arguments: %v", err)
+			}
+
+			now := time.Now()
+			if args.Timezone != "" {
+				location, err := time.LoadLocation(args.Timezone)
+				if err != nil {
+					return "", fmt.Errorf("unknown time zone %q", args.Timezone)
+				}
+				now = now.In(location)
+			}
+			return now.Format("Monday, 2 January 2006 15:04:05 MST"), nil
+		},
+	}
+}
+
+// readFile reads files below dir. Paths that leave it, including through
+// symlinks, are refused.
+func readFile(dir string) Tool {
+	return Tool{
+		Name:        "read_file",
+		Description: "Read a text file from the user's shared files directory.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"path": {"type": "string", "description": "Path relative to the shared files directory"}
+			},
+			"required": ["path"]
+		}`),
+		Run: func(ctx context.Context, raw json.RawMessage) (string, error) {
+			var args struct {
+				Path string `json:"path"`
+			}
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return "", fmt.Errorf("invalid arguments: %v", err)
+			}
+
+			root, err := os.OpenRoot(dir)
+			if err != nil {
+				return "", fmt.Errorf("shared files directory is not available: %v", err)
+			}
+			defer root.Close()
+
+			file, err := root.Open(args.Path)
+			if err != nil {
+				return "", fmt.Errorf("cannot open %s: %v", args.Path, err)
+			}
+			defer file.Close()
+
+			data, err := io.ReadAll(io.LimitReader(file, maxFileBytes+1))
+			if err != nil {
+				return "", fmt.Errorf("cannot read %s: %v", args.Path, err)
+			}
+			if len(data) > maxFileBytes {
+				return string(data[:maxFileBytes]) + "\n[truncated]", nil
+			}
+			return string(data), nil
+		},
+	}
+}
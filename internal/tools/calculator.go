@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"strconv"
+)
+
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+var functions = map[string]func(float64) float64{
+	"sqrt":  math.Sqrt,
+	"abs":   math.Abs,
+	"ln":    math.Log,
+	"log10": math.Log10,
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"round": math.Round,
+}
+
+var binaryFunctions = map[string]func(float64, float64) float64{
+	"pow": math.Pow,
+	"min": math.Min,
+	"max": math.Max,
+}
+
+// Evaluate computes an arithmetic expression. It borrows Go's expression
+// parser, so precedence follows Go; powers are written pow(x, y).
+func Evaluate(expression string) (float64, error) {
+	node, err := parser.ParseExpr(expression)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expression: %v", err)
+	}
+	value, err := evaluate(node)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("result is not a finite number")
+	}
+	return value, nil
+}
+
+func evaluate(node ast.Expr) (float64, error) {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		if n.Kind != token.INT && n.Kind != token.FLOAT {
+			return 0, fmt.Errorf("unsupported literal %s", n.Value)
+		}
+		return strconv.ParseFloat(n.Value, 64)
+
+	case *ast.Ident:
+		if value, ok := constants[n.Name]; ok {
+			return value, nil
+		}
+		return 0, fmt.Errorf("unknown name %q", n.Name)
+
+	case *ast.ParenExpr:
+		return evaluate(n.X)
+
+	case *ast.UnaryExpr:
+		x, err := evaluate(n.X)
+		if err != nil {
+			return 0, err
+		}
+		switch n.Op {
+		case token.ADD:
+			return x, nil
+		case token.SUB:
+			return -x, nil
+		}
+		return 0, fmt.Errorf("unsupported operator %s", n.Op)
+
+	case *ast.BinaryExpr:
+		x, err := evaluate(n.X)
+		if err != nil {
+			return 0, err
+		}
+		y, err := evaluate(n.Y)
+		if err != nil {
+			return 0, err
+		}
+		switch n.Op {
+		case token.ADD:
+			return x + y, nil
+		case token.SUB:
+			return x - y, nil
+		case token.MUL:
+			return x * y, nil
+		case token.QUO:
+			if y == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return x / y, nil
+		case token.REM:
+			if y == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return math.Mod(x, y), nil
+		case token.XOR:
+			return 0, fmt.Errorf("use pow(x, y) for powers")
+		}
+		return 0, fmt.Errorf("unsupported operator %s", n.Op)
+
+	case *ast.CallExpr:
+		name, ok := n.Fun.(*ast.Ident)
+		if !ok {
+			return 0, fmt.Errorf("unsupported function call")
+		}
+		var err error
+		args := make([]float64, len(n.Args))
+		for i, arg := range n.Args {
+			if args[i], err = evaluate(arg); err != nil {
+				return 0, err
+			}
+		}
+		if fn, ok := functions[name.Name]; ok && len(args) == 1 {
+			return fn(args[0]), nil
+		}
+		if fn, ok := binaryFunctions[name.Name]; ok && len(args) == 2 {
+			return fn(args[0], args[1]), nil
+		}
+		return 0, fmt.Errorf("unknown function %s with %d arguments", name.Name, len(args))
+	}
+
+	return 0, fmt.Errorf("unsupported expression")
+}
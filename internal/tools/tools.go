@@ -0,0 +1,156 @@
+// Package tools holds the local tools a model may call and runs the
+// tool-calling loop against a provider.
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+)
+
+// MaxRounds caps how many times the model may call tools before it has to
+// answer.
+const MaxRounds = 5
+
+// Tool is a Go function the model can call. Parameters is the JSON Schema
+// of the arguments object passed to Run.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage
+	Run         func(ctx context.Context, args json.RawMessage) (string, error)
+}
+
+// Registry is an ordered set of tools.
+type Registry struct {
+	tools []Tool
+}
+
+func NewRegistry(tools ...Tool) *Registry {
+	r := &Registry{}
+	for _, tool := range tools {
+		r.Register(tool)
+	}
+	return r
+}
+
+// Register adds a tool, replacing any tool of the same name.
+func (r *Registry) Register(tool Tool) {
+	for i, existing := range r.tools {
+		if existing.Name == tool.Name {
+			r.tools[i] = tool
+			return
+		}
+	}
+	r.tools = append(r.tools, tool)
+}
+
+func (r *Registry) Get(name string) (Tool, bool) {
+	for _, tool := range r.tools {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return Tool{}, false
+}
+
+// Specs describes the tools to a provider.
+func (r *Registry) Specs() []provider.ToolSpec {
+	specs := make([]provider.ToolSpec, 0, len(r.tools))
+	for _, tool := range r.tools {
+		specs = append(specs, provider.ToolSpec{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.Parameters,
+		})
+	}
+	return specs
+}
+
+// Call runs a tool call and returns the "tool" message answering it.
+// Failures are reported to the model in the message rather than returned,
+// so it can correct itself.
+func (r *Registry) Call(ctx context.Context, call storage.ToolCall) storage.ChatMessage {
+	result := storage.ChatMessage{
+		Role:       "tool",
+		ToolCallID: call.ID,
+		Timestamp:  time.Now(),
+	}
+
+	tool, ok := r.Get(call.Name)
+	if !ok {
+		result.Content = fmt.Sprintf("Error: unknown tool %q", call.Name)
+		return result
+	}
+
+	args := json.RawMessage(call.Arguments)
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	output, err := tool.Run(ctx, args)
+	if err != nil {
+		result.Content = fmt.Sprintf("Error: %v", err)
+		return result
+	}
+	result.Content = output
+	return result
+}
+
+// Converse sends req with the registry's tools and runs every tool the
+// model asks for, feeding the results back until it answers without
+// calling one. onMessage receives each tool-calling assistant message and
+// each tool result, in order, so the caller can record them. The returned
+// usage is totalled over all rounds.
+func (r *Registry) Converse(ctx context.Context, p provider.Provider, req provider.ChatRequest, onDelta func(string), onMessage func(storage.ChatMessage)) (*provider.ChatResponse, error) {
+	messages := append([]storage.ChatMessage{}, req.Messages...)
+	var total *storage.Usage
+
+	for round := 0; ; round++ {
+		req.Messages = messages
+		req.Tools = r.Specs()
+		if round == MaxRounds {
+			// Out of rounds: take the tools away so the model has to answer
+			req.Tools = nil
+		}
+
+		var resp *provider.ChatResponse
+		var err error
+		if p.Capabilities().Streaming {
+			resp, err = p.Stream(ctx, req, onDelta)
+		} else {
+			resp, err = p.Chat(ctx, req)
+		}
+		if resp != nil && resp.Usage != nil {
+			if total == nil {
+				total = &storage.Usage{}
+			}
+			total.Add(*resp.Usage)
+			resp.Usage = total
+		}
+		if err != nil || len(resp.ToolCalls) == 0 {
+			return resp, err
+		}
+
+		call := storage.ChatMessage{
+			Role:      "assistant",
+			Content:   resp.Content,
+			ToolCalls: resp.ToolCalls,
+			Timestamp: time.Now(),
+		}
+		messages = append(messages, call)
+		onMessage(call)
+
+		for _, toolCall := range resp.ToolCalls {
+			result := r.Call(ctx, toolCall)
+			if err := ctx.Err(); err != nil {
+				return &provider.ChatResponse{Usage: total}, err
+			}
+			messages = append(messages, result)
+			onMessage(result)
+		}
+	}
+}
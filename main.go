@@ -3,14 +3,34 @@ package main
 import (
 	"log"
 
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/anthropic"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/config"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/gemini"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/groq"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/ollama"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
 	"github.com/Rohan-Shah-312003/tui-gpt/ui"
 	"github.com/joho/godotenv"
 )
 
 func main() {
-	err := godotenv.Load()
+	// A missing .env is fine: keys may come from the environment, and
+	// local providers such as Ollama need none at all.
+	_ = godotenv.Load()
+
+	provider.Register(groq.NewClient())
+	provider.Register(gemini.NewClient())
+	provider.Register(anthropic.NewClient())
+	provider.Register(ollama.NewClient())
+
+	// Endpoint profiles come last, so one named after a built-in provider
+	// replaces it, e.g. to send "groq" through a gateway.
+	profiles, err := config.LoadProfiles()
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		log.Fatalf("failed to load endpoint profiles: %v", err)
+	}
+	for _, profile := range profiles {
+		provider.Register(groq.NewProfileClient(profile))
 	}
 
 	app := ui.NewApp()
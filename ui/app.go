@@ -1,10 +1,16 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/config"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/history"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/jsonmode"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
 	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/tools"
 	"github.com/rivo/tview"
 )
 
@@ -20,23 +26,65 @@ type App struct {
 	helpModal      *HelpModal
 	chatListModal  *ChatListModal
 	modelListModal *ModelListModal
+	personaModal   *PersonaModal
+	settingsModal  *SettingsModal
+	compareModal   *CompareModal
 
 	// State
-	isShowingChatList  bool
-	isShowingModelList bool
+	isShowingChatList    bool
+	isShowingModelList   bool
+	isShowingPersonaList bool
+	isShowingSettings    bool
+	isShowingCompare     bool
+	activeRequest        *activeRequest
+	// ID of the earlier user message being edited into a new branch
+	editing string
+
+	// Files attached to the message being written, sent with it, and the
+	// recording it was transcribed from
+	attachments   []storage.Attachment
+	transcription *storage.Transcription
+
+	// Whether the thinking of reasoning models is shown in full
+	showReasoning bool
+
+	// Global preferences from settings.json
+	settings config.Settings
+
+	// Tools the model may call in chats that enable them
+	tools *tools.Registry
+
+	// Token usage of every saved chat other than the current one
+	pastUsage storage.Usage
+
+	// Provider and model given to new chats
+	defaultProvider string
+	defaultModel    string
 
 	// Enhanced features
 	clipboard      string
 	lastCopiedTime time.Time
 }
 
+// activeRequest tracks the generation currently in flight so it can be
+// cancelled from the keyboard.
+type activeRequest struct {
+	cancel context.CancelFunc
+}
+
 func NewApp() *App {
-	return &App{
+	a := &App{
 		app:         tview.NewApplication(),
 		pages:       tview.NewPages(),
 		chatHistory: []storage.ChatMessage{},
 		clipboard:   "",
+		tools:       tools.Builtin(),
+	}
+	if p := provider.Default(); p != nil {
+		a.defaultProvider = p.Name()
+		a.defaultModel = p.DefaultModel()
 	}
+	return a
 }
 
 func (a *App) Start() error {
@@ -45,20 +93,57 @@ func (a *App) Start() error {
 		return fmt.Errorf("failed to initialize storage: %v", err)
 	}
 
+	// Unreadable settings fall back to the provider defaults
+	a.settings, _ = config.LoadSettings()
+
 	a.startNewChat()
+	a.refreshUsageTotals()
 	a.setupUI()
 	a.setupKeyBindings()
 
+	go a.modelListModal.refresh()
+
 	return a.app.SetRoot(a.pages, true).EnableMouse(true).Run()
 }
 
 func (a *App) startNewChat() {
 	a.currentSession = &storage.ChatSession{
+		Provider:  a.defaultProvider,
+		Model:     a.defaultModel,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		Messages:  []storage.ChatMessage{},
 	}
 	a.chatHistory = []storage.ChatMessage{}
+	a.editing = ""
+}
+
+// applyPersona copies a persona's system prompt and parameters onto the
+// current session, and switches to its model when that provider exists.
+func (a *App) applyPersona(persona config.Persona) {
+	a.currentSession.Persona = persona.Name
+	a.currentSession.SystemPrompt = persona.SystemPrompt
+	if persona.Params != nil {
+		params := *persona.Params
+		a.currentSession.Params = &params
+	}
+	if persona.Provider == "" && persona.Model == "" {
+		return
+	}
+
+	providerName := persona.Provider
+	if providerName == "" {
+		providerName = a.currentSession.Provider
+	}
+	p, err := provider.Get(providerName)
+	if err != nil {
+		return
+	}
+	a.currentSession.Provider = providerName
+	a.currentSession.Model = persona.Model
+	if persona.Model == "" {
+		a.currentSession.Model = p.DefaultModel()
+	}
 }
 
 func (a *App) setupUI() {
@@ -66,11 +151,106 @@ func (a *App) setupUI() {
 	a.helpModal = NewHelpModal(a)
 	a.chatListModal = NewChatListModal(a)
 	a.modelListModal = NewModelListModal(a)
+	a.personaModal = NewPersonaModal(a)
+	a.settingsModal = NewSettingsModal(a)
+	a.compareModal = NewCompareModal(a)
 
 	a.pages.AddPage("main", a.mainLayout.Create(), true, true)
 	a.pages.AddPage("help", a.helpModal.Create(), true, false)
 	a.pages.AddPage("chatlist", a.chatListModal.Create(), true, false)
 	a.pages.AddPage("modellist", a.modelListModal.Create(), true, false)
+	a.pages.AddPage("personalist", a.personaModal.Create(), true, false)
+	a.pages.AddPage("settings", a.settingsModal.Create(), true, false)
+	a.pages.AddPage("compare", a.compareModal.Create(), true, false)
+}
+
+// isShowingModal reports whether a modal that takes over the keyboard is
+// open, in which case the global shortcuts are ignored.
+func (a *App) isShowingModal() bool {
+	return a.isShowingChatList || a.isShowingModelList || a.isShowingPersonaList || a.isShowingSettings || a.isShowingCompare
+}
+
+// generationParams returns the parameters for the next reply: the global
+// defaults overridden by those of the current session.
+func (a *App) generationParams() storage.GenerationParams {
+	params := a.settings.Params
+	if a.currentSession.Params != nil {
+		params = params.Merge(*a.currentSession.Params)
+	}
+	return params
+}
+
+// contextStrategy returns how to trim the current session's history once it
+// outgrows the context window.
+func (a *App) contextStrategy() string {
+	if a.currentSession.ContextStrategy != "" {
+		return a.currentSession.ContextStrategy
+	}
+	if a.settings.ContextStrategy != "" {
+		return a.settings.ContextStrategy
+	}
+	return history.StrategyDropOldest
+}
+
+// contextBudget returns how many tokens of history a request to the model
+// may carry, leaving room for the system prompt, the instructions and tool
+// definitions sent with it, and the reply. The reply limit takes at most
+// half the window, so that a max_tokens as large as the window does not
+// crowd out the history.
+func (a *App) contextBudget(providerName, model string, params storage.GenerationParams, jsonMode, useTools bool) int {
+	window := a.modelListModal.contextWindow(providerName, model)
+	reserve := min(window/4, 1024)
+	if params.MaxTokens != nil {
+		reserve = min(*params.MaxTokens, window/2)
+	}
+	budget := window - reserve - history.EstimateTokens(a.currentSession.SystemPrompt)
+	if jsonMode {
+		budget -= history.EstimateTokens(jsonmode.Instructions(a.currentSession.JSONSchema))
+	}
+	if useTools {
+		budget -= history.EstimateTools(a.tools.Specs())
+	}
+	return max(budget, history.MinBudget)
+}
+
+// refreshUsageTotals recomputes the all-time token usage from saved chats,
+// leaving out the current chat, whose live totals are added on display.
+func (a *App) refreshUsageTotals() {
+	usage, err := a.storageManager.TotalUsage(a.currentSession.ID)
+	if err != nil {
+		return
+	}
+	a.pastUsage = usage
+}
+
+// sessionProvider resolves the provider and model the current session talks
+// to. Chats saved before providers were recorded get the current defaults.
+func (a *App) sessionProvider() (provider.Provider, string, error) {
+	if a.currentSession.Provider == "" {
+		a.currentSession.Provider = a.defaultProvider
+		a.currentSession.Model = a.defaultModel
+	}
+
+	p, err := provider.Get(a.currentSession.Provider)
+	if err != nil {
+		return nil, "", err
+	}
+
+	model := a.currentSession.Model
+	if model == "" {
+		model = p.DefaultModel()
+		a.currentSession.Model = model
+	}
+	return p, model, nil
+}
+
+// setSessionModel switches the current session to the given provider and
+// model, and makes them the default for new chats.
+func (a *App) setSessionModel(providerName, model string) {
+	a.currentSession.Provider = providerName
+	a.currentSession.Model = model
+	a.defaultProvider = providerName
+	a.defaultModel = model
 }
 
 // Clipboard functionality
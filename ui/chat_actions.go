@@ -1,58 +1,432 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/Rohan-Shah-312003/tui-gpt/internal/groq"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/continuation"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/history"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/jsonmode"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/reasoning"
 	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/titles"
+	"github.com/rivo/tview"
 )
 
+// describeTimeout bounds the background request for a chat's title, so a
+// slow model does not leave it pending.
+const describeTimeout = 30 * time.Second
+
+// transcribeTimeout bounds the upload and transcription of an audio file.
+const transcribeTimeout = 2 * time.Minute
+
+// maxAudioSize is the largest recording Groq's free tier transcribes.
+const maxAudioSize = 25 << 20
+
+// audioFormats are the extensions of the recordings that can be
+// transcribed.
+var audioFormats = []string{".wav", ".mp3", ".m4a", ".flac", ".ogg", ".opus", ".webm", ".mp4", ".mpeg", ".mpga"}
+
 func (a *App) sendMessage() {
+	if a.activeRequest != nil {
+		a.mainLayout.updateStatus("[yellow]⏳ Still answering... press Esc to cancel")
+		return
+	}
+
 	prompt := strings.TrimSpace(a.mainLayout.inputField.GetText())
 	if prompt == "" {
 		a.mainLayout.updateStatus("[red]⚠️  Empty message!")
 		return
 	}
 
+	if command, args, ok := slashCommand(prompt); ok {
+		a.runCommand(command, args)
+		return
+	}
+
+	chatProvider, model, ok := a.readyProvider()
+	if !ok {
+		return
+	}
+
+	if len(a.attachments) > 0 && !a.modelListModal.supportsVision(chatProvider.Name(), model) {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]🖼️  %s cannot read images - pick a vision model with Ctrl+- or remove them with /image", model))
+		return
+	}
+
+	if a.editing != "" {
+		a.forkAt(a.editing)
+	}
+
 	userMsg := storage.ChatMessage{
-		Role:      "user",
-		Content:   prompt,
-		Timestamp: time.Now(),
+		Role:          "user",
+		Content:       prompt,
+		Timestamp:     time.Now(),
+		Attachments:   a.attachments,
+		Transcription: a.transcription,
 	}
 	a.chatHistory = append(a.chatHistory, userMsg)
 	a.currentSession.Messages = a.chatHistory
+	a.currentSession.Link()
 
+	a.mainLayout.inputField.SetText("")
+	a.transcription = nil
+	a.setAttachments(nil)
+	a.requestReply(chatProvider, model, replyOptions{})
+}
+
+// slashCommand splits input such as "/system be brief" into the command
+// name and its arguments.
+func slashCommand(input string) (string, string, bool) {
+	if !strings.HasPrefix(input, "/") {
+		return "", "", false
+	}
+	command, args, _ := strings.Cut(input[1:], " ")
+	return command, strings.TrimSpace(args), true
+}
+
+// runCommand carries out a slash command typed into the input field.
+func (a *App) runCommand(command, args string) {
+	switch command {
+	case "system":
+		a.currentSession.SystemPrompt = args
+		if args == "" {
+			a.mainLayout.updateStatus("[blue]🧭 System prompt cleared")
+		} else {
+			a.mainLayout.updateStatus("[blue]🧭 System prompt set")
+		}
+	case "tools":
+		a.currentSession.Tools = !a.currentSession.Tools
+		if a.currentSession.Tools {
+			a.mainLayout.updateStatus("[blue]🔧 Tools enabled for this chat")
+		} else {
+			a.mainLayout.updateStatus("[blue]🔧 Tools disabled for this chat")
+		}
+	case "json":
+		a.currentSession.JSONMode = !a.currentSession.JSONMode
+		if a.currentSession.JSONMode {
+			a.mainLayout.updateStatus("[blue]🧾 JSON mode on for this chat")
+		} else {
+			a.mainLayout.updateStatus("[blue]🧾 JSON mode off for this chat")
+		}
+	case "compare":
+		a.compare(args)
+		return
+	case "regen":
+		a.regenerateCommand(args)
+		return
+	case "profile":
+		if !a.switchProfile(args) {
+			return
+		}
+	case "audio":
+		a.transcribe(args)
+		return
+	case "image":
+		if args == "" {
+			a.setAttachments(nil)
+			a.mainLayout.updateStatus("[blue]🖼️  Attachments removed")
+			break
+		}
+		image, err := storage.LoadImage(args)
+		if err != nil {
+			a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ %v", err))
+			return
+		}
+		a.setAttachments(append(a.attachments, image))
+		a.mainLayout.updateStatus(fmt.Sprintf("[blue]🖼️  Attached %s - it is sent with your next message", attachmentLabel(image)))
+	case "schema":
+		if args == "" {
+			a.currentSession.JSONSchema = nil
+			a.mainLayout.updateStatus("[blue]🧾 JSON schema removed")
+			break
+		}
+		schema, err := os.ReadFile(args)
+		if err != nil {
+			a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ Failed to read schema: %v", err))
+			return
+		}
+		if _, err := jsonmode.ParseSchema(schema); err != nil {
+			a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ %v", err))
+			return
+		}
+		a.currentSession.JSONSchema = schema
+		a.currentSession.JSONMode = true
+		a.mainLayout.updateStatus(fmt.Sprintf("[blue]🧾 JSON mode on, checked against %s", filepath.Base(args)))
+	default:
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ Unknown command: /%s", command))
+		return
+	}
 	a.mainLayout.inputField.SetText("")
+	a.mainLayout.updateConversationView()
+	a.mainLayout.updateSidebar()
+}
+
+// readyProvider resolves the session's provider and model and checks that
+// they can be used, reporting any problem in the status bar.
+func (a *App) readyProvider() (provider.Provider, string, bool) {
+	chatProvider, model, err := a.sessionProvider()
+	if err != nil {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ %v", err))
+		return nil, "", false
+	}
+	if !chatProvider.Configured() {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ %s is not configured - set its API key or pick another model with Ctrl+-", chatProvider.Name()))
+		return nil, "", false
+	}
+	if a.modelListModal.isUnavailable(chatProvider.Name(), model) {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]🚫 %s is no longer available - pick another model with Ctrl+-", model))
+		return nil, "", false
+	}
+	if hasImages(a.chatHistory) && !a.modelListModal.supportsVision(chatProvider.Name(), model) {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]🖼️  This chat has images, which %s cannot read - pick a vision model with Ctrl+-", model))
+		return nil, "", false
+	}
+	return chatProvider, model, true
+}
+
+// hasImages reports whether any message of history has images attached.
+func hasImages(history []storage.ChatMessage) bool {
+	for _, msg := range history {
+		if len(msg.Images()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// setAttachments replaces the files attached to the message being
+// written.
+func (a *App) setAttachments(attachments []storage.Attachment) {
+	a.attachments = attachments
+	a.mainLayout.updateInputTitle()
+}
+
+// replyOptions adjust a single request.
+type replyOptions struct {
+	// The reply being regenerated, kept as an alternative of the new one
+	previous *storage.ChatMessage
+	// Parameters overriding the session's for this request only
+	params storage.GenerationParams
+}
+
+// requestReply sends the session's system prompt and as much of the
+// current history as fits the model's context window to the provider, and
+// streams the answer into a new assistant message.
+func (a *App) requestReply(chatProvider provider.Provider, model string, opts replyOptions) {
 	a.mainLayout.updateConversationView()
 	a.mainLayout.updateStatus("[yellow]🤔 AI is thinking...")
 	a.mainLayout.updateSidebar()
 
+	session := a.currentSession
+	params := a.generationParams().Merge(opts.params)
+	useTools := session.Tools && chatProvider.Capabilities().Tools
+	window := contextWindow{
+		messages: history.Sendable(a.chatHistory),
+		budget:   a.contextBudget(chatProvider.Name(), model, params, session.JSONMode, useTools),
+		strategy: a.contextStrategy(),
+	}
+	if session.Summary != nil {
+		window.summary = *session.Summary
+	}
+
+	// Placeholder for the live assistant reply, filled in as deltas arrive.
+	// Its model tells how to pick out thinking that streams in untagged.
+	replyIndex := len(a.chatHistory)
+	a.chatHistory = append(a.chatHistory, storage.ChatMessage{
+		Role:      "assistant",
+		Timestamp: time.Now(),
+		Model:     model,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	request := &activeRequest{cancel: cancel}
+	a.activeRequest = request
+
+	started := time.Now()
+
 	go func() {
-		reply, err := groq.SendPrompt(prompt)
+		defer cancel()
+		onDelta := func(delta string) {
+			a.app.QueueUpdateDraw(func() {
+				if a.currentSession != session {
+					return
+				}
+				if a.mainLayout.appendToMessage(replyIndex, delta) {
+					a.mainLayout.updateStatus("[yellow]✍️  AI is responding...")
+				}
+				a.showRateLimit(chatProvider)
+			})
+		}
+
+		messages, stats := a.fitContext(ctx, chatProvider, model, &window)
+		if session.SystemPrompt != "" {
+			messages = append([]storage.ChatMessage{{Role: "system", Content: session.SystemPrompt}}, messages...)
+		}
+
+		// Each tool call and result takes the place of the live reply, which
+		// moves down below it
+		onToolMessage := func(msg storage.ChatMessage) {
+			a.app.QueueUpdateDraw(func() {
+				if a.currentSession != session || replyIndex >= len(a.chatHistory) {
+					return
+				}
+				a.chatHistory[replyIndex] = msg
+				a.chatHistory = append(a.chatHistory, storage.ChatMessage{
+					Role:      "assistant",
+					Timestamp: time.Now(),
+				})
+				replyIndex = len(a.chatHistory) - 1
+				a.currentSession.Messages = a.chatHistory
+				a.mainLayout.updateConversationView()
+				if len(msg.ToolCalls) > 0 {
+					a.mainLayout.updateStatus(fmt.Sprintf("[yellow]🔧 Running %s...", msg.ToolCalls[0].Name))
+				}
+			})
+		}
+
+		// A reply that failed validation is cleared before the next attempt
+		onRetry := func(problems []string) {
+			a.app.QueueUpdateDraw(func() {
+				if a.currentSession != session || replyIndex >= len(a.chatHistory) {
+					return
+				}
+				a.chatHistory[replyIndex].Content = ""
+				a.mainLayout.updateConversationView()
+				a.mainLayout.updateStatus(fmt.Sprintf("[yellow]🔁 Reply did not match the schema (%s), asking again...", problems[0]))
+			})
+		}
+
+		chatRequest := provider.ChatRequest{Model: model, Messages: messages, Params: params}
+		var resp *provider.ChatResponse
+		var continued int
+		var err error
+		switch {
+		case session.JSONMode:
+			resp, err = jsonmode.Generate(ctx, chatProvider, chatRequest, session.JSONSchema, onDelta, onRetry)
+		case useTools:
+			resp, err = a.tools.Converse(ctx, chatProvider, chatRequest, onDelta, onToolMessage)
+		case a.settings.AutoContinue:
+			resp, continued, err = continuation.Generate(ctx, chatProvider, chatRequest, onDelta)
+		case chatProvider.Capabilities().Streaming:
+			resp, err = chatProvider.Stream(ctx, chatRequest, onDelta)
+		default:
+			resp, err = chatProvider.Chat(ctx, chatRequest)
+		}
+
+		reply, thinking := "", ""
+		finishReason := ""
+		var usage *storage.Usage
+		if resp != nil {
+			reply, thinking = reasoning.SplitFor(model, resp.Content)
+			thinking = reasoning.Join(resp.Reasoning, thinking)
+			finishReason = resp.FinishReason
+			usage = resp.Usage
+		}
+		latency := time.Since(started)
+
 		a.app.QueueUpdateDraw(func() {
-			if err != nil {
+			if a.activeRequest == request {
+				a.activeRequest = nil
+			}
+			a.showRateLimit(chatProvider)
+			if window.summary.Content != "" {
+				summary := window.summary
+				session.Summary = &summary
+			}
+			if a.currentSession != session || replyIndex >= len(a.chatHistory) {
+				return
+			}
+			previous := opts.previous
+			if errors.Is(err, context.Canceled) && previous != nil && reply == "" && thinking == "" {
+				// Nothing new arrived, so the earlier version stays as it was
+				a.chatHistory[replyIndex] = *previous
+				a.mainLayout.updateStatus("[yellow]⏹️  Request cancelled")
+			} else if errors.Is(err, context.Canceled) {
+				// Keep the partial output, marked so it is not mistaken for a full reply
+				a.chatHistory[replyIndex].Content = reply
+				a.chatHistory[replyIndex].Reasoning = thinking
+				a.chatHistory[replyIndex].Cancelled = true
+				a.chatHistory[replyIndex].Model = model
+				a.chatHistory[replyIndex].Params = usedParams(params)
+				a.chatHistory[replyIndex].Context = stats
+				if previous != nil {
+					a.chatHistory[replyIndex] = previous.AddVersion(a.chatHistory[replyIndex])
+				}
+				a.mainLayout.updateStatus("[yellow]⏹️  Request cancelled")
+			} else if err != nil && previous != nil {
+				// A failed regeneration leaves the earlier version in place
+				a.chatHistory[replyIndex] = *previous
+				status := "[red]❌ Regenerate failed: " + tview.Escape(err.Error())
+				var hinted provider.HintedError
+				if errors.As(err, &hinted) {
+					status += " 💡 " + tview.Escape(hinted.Hint())
+				}
+				a.mainLayout.updateStatus(status)
+			} else if err != nil {
+				// Keep whatever partial output arrived before the failure
+				partial := &a.chatHistory[replyIndex]
+				partial.Content, partial.Reasoning = reasoning.SplitFor(model, partial.Content)
+				if partial.Content == "" && partial.Reasoning == "" {
+					// Without streaming nothing arrived early, but a reply
+					// rejected as invalid JSON comes back with the error
+					partial.Content, partial.Reasoning = reply, thinking
+					partial.Model = model
+				}
+				if partial.Content == "" && partial.Reasoning == "" {
+					a.chatHistory = append(a.chatHistory[:replyIndex], a.chatHistory[replyIndex+1:]...)
+				}
 				errorMsg := storage.ChatMessage{
 					Role:      "error",
 					Content:   fmt.Sprintf("Error: %v", err),
 					Timestamp: time.Now(),
 				}
+				var hinted provider.HintedError
+				if errors.As(err, &hinted) {
+					errorMsg.Hint = hinted.Hint()
+				}
 				a.chatHistory = append(a.chatHistory, errorMsg)
 				a.mainLayout.updateStatus("[red]❌ Error occurred!")
 			} else {
-				aiMsg := storage.ChatMessage{
-					Role:      "assistant",
-					Content:   reply,
-					Timestamp: time.Now(),
+				a.chatHistory[replyIndex].Content = reply
+				a.chatHistory[replyIndex].Reasoning = thinking
+				a.chatHistory[replyIndex].Timestamp = time.Now()
+				a.chatHistory[replyIndex].Model = model
+				a.chatHistory[replyIndex].Usage = usage
+				a.chatHistory[replyIndex].LatencyMs = latency.Milliseconds()
+				a.chatHistory[replyIndex].Params = usedParams(params)
+				a.chatHistory[replyIndex].Context = stats
+				a.chatHistory[replyIndex].FinishReason = finishReason
+				a.chatHistory[replyIndex].Continued = continued
+				if previous != nil {
+					a.chatHistory[replyIndex] = previous.AddVersion(a.chatHistory[replyIndex])
+				}
+				a.describeChat(session)
+				switch count := len(a.chatHistory[replyIndex].Alternatives) + 1; {
+				case finishReason == provider.FinishLength && a.settings.AutoContinue:
+					a.mainLayout.updateStatus(fmt.Sprintf("[yellow]✂️  Reply still cut off after %d follow-ups - raise max_tokens in Ctrl+P", continued))
+				case finishReason == provider.FinishLength:
+					a.mainLayout.updateStatus("[yellow]✂️  Reply cut off by max_tokens - raise it or turn on auto-continue in Ctrl+P")
+				case stats != nil:
+					a.mainLayout.updateStatus(fmt.Sprintf("[green]✅ Response received! [yellow](sent %d of %d messages)", stats.Sent, stats.Total))
+				case count > 1:
+					a.mainLayout.updateStatus(fmt.Sprintf("[green]✅ Reply %d of %d • press < or > in the conversation to compare", count, count))
+				default:
+					a.mainLayout.updateStatus("[green]✅ Response received!")
 				}
-				a.chatHistory = append(a.chatHistory, aiMsg)
-				a.mainLayout.updateStatus("[green]✅ Response received!")
 			}
 			a.currentSession.Messages = a.chatHistory
 			a.mainLayout.updateConversationView()
 			a.mainLayout.updateSidebar()
-			go a.saveCurrentChat()
+			a.saveCurrentChatInBackground()
 			go func() {
 				time.Sleep(3 * time.Second)
 				a.app.QueueUpdateDraw(func() {
@@ -63,9 +437,525 @@ func (a *App) sendMessage() {
 	}()
 }
 
+// contextWindow is what a request needs to trim the session's history to
+// the model's context window.
+type contextWindow struct {
+	messages []storage.ChatMessage
+	budget   int
+	strategy string
+	// The session's summary, replaced when a new one is made
+	summary storage.ContextSummary
+}
+
+// fitContext trims the history to the token budget with the window's
+// strategy. It returns the messages to send, and stats when some had to be
+// left out. Summaries are made on the request goroutine; if one fails the
+// oldest messages are simply dropped.
+func (a *App) fitContext(ctx context.Context, chatProvider provider.Provider, model string, window *contextWindow) ([]storage.ChatMessage, *storage.ContextStats) {
+	if window.strategy != history.StrategySummarize {
+		messages, dropped := history.Fit(window.messages, window.budget, window.strategy)
+		if dropped == 0 {
+			return messages, nil
+		}
+		return messages, &storage.ContextStats{Sent: len(messages), Total: len(window.messages), Strategy: window.strategy}
+	}
+
+	messages, dropped := history.Fit(window.messages, window.budget-history.SummaryReserve, history.StrategyDropOldest)
+	if dropped == 0 {
+		return messages, nil
+	}
+	stats := &storage.ContextStats{Sent: len(messages), Total: len(window.messages), Strategy: window.strategy}
+
+	// Extend the running summary with whatever was dropped since it was made
+	summary := window.summary
+	if summary.Covers != dropped {
+		a.app.QueueUpdateDraw(func() {
+			a.mainLayout.updateStatus("[yellow]🗜️  Summarizing earlier messages...")
+		})
+		previous, from := summary.Content, summary.Covers
+		if summary.Covers > dropped {
+			previous, from = "", 0
+		}
+		content, err := history.Summarize(ctx, chatProvider, model, previous, window.messages[from:dropped], window.budget)
+		if err != nil {
+			return messages, stats
+		}
+		summary = storage.ContextSummary{Content: content, Covers: dropped}
+		window.summary = summary
+	}
+
+	stats.Summarized = dropped
+	return append([]storage.ChatMessage{history.SummaryNote(summary.Content)}, messages...), stats
+}
+
+// usedParams returns the parameters to record on a reply, or nil when the
+// provider defaults were used.
+func usedParams(params storage.GenerationParams) *storage.GenerationParams {
+	if params.IsZero() {
+		return nil
+	}
+	return &params
+}
+
+// retryOrRegenerate retries after an error, and otherwise regenerates the
+// last reply with the session's model.
+func (a *App) retryOrRegenerate() {
+	if len(a.chatHistory) > 0 && a.chatHistory[len(a.chatHistory)-1].Role == "error" {
+		a.retryLastRequest()
+		return
+	}
+	chatProvider, model, ok := a.readyProvider()
+	if !ok {
+		return
+	}
+	a.regenerateLastReply(chatProvider, model, replyOptions{})
+}
+
+// retryLastRequest drops a failed reply and asks the provider again with
+// the same history.
+func (a *App) retryLastRequest() {
+	if a.activeRequest != nil {
+		a.mainLayout.updateStatus("[yellow]⏳ Still answering... press Esc to cancel")
+		return
+	}
+	if len(a.chatHistory) == 0 || a.chatHistory[len(a.chatHistory)-1].Role != "error" {
+		a.mainLayout.updateStatus("[yellow]Nothing to retry")
+		return
+	}
+
+	lastUser := -1
+	for i := len(a.chatHistory) - 1; i >= 0; i-- {
+		if a.chatHistory[i].Role == "user" {
+			lastUser = i
+			break
+		}
+	}
+	if lastUser < 0 {
+		a.mainLayout.updateStatus("[yellow]Nothing to retry")
+		return
+	}
+
+	chatProvider, model, ok := a.readyProvider()
+	if !ok {
+		return
+	}
+
+	// Drop the error and any partial reply that came before it
+	a.chatHistory = a.chatHistory[:lastUser+1]
+	a.currentSession.Messages = a.chatHistory
+	a.requestReply(chatProvider, model, replyOptions{})
+}
+
+// regenerateLastReply asks for a new version of the last reply. The reply
+// it replaces is kept as an alternative that can be switched back to.
+func (a *App) regenerateLastReply(chatProvider provider.Provider, model string, opts replyOptions) {
+	if a.activeRequest != nil {
+		a.mainLayout.updateStatus("[yellow]⏳ Still answering... press Esc to cancel")
+		return
+	}
+
+	last := len(a.chatHistory) - 1
+	if last < 1 || a.chatHistory[last].Role != "assistant" || a.chatHistory[last-1].Role != "user" {
+		a.mainLayout.updateStatus("[yellow]Nothing to regenerate - only a plain reply to the last message can be")
+		return
+	}
+
+	previous := a.chatHistory[last]
+	opts.previous = &previous
+	a.chatHistory = a.chatHistory[:last]
+	a.currentSession.Messages = a.chatHistory
+	a.requestReply(chatProvider, model, opts)
+}
+
+// regenerateCommand handles "/regen [model] [temperature]". A number is
+// taken as a one-off temperature and anything else as a model of the
+// session's provider to use for this reply only.
+func (a *App) regenerateCommand(args string) {
+	chatProvider, model, ok := a.readyProvider()
+	if !ok {
+		return
+	}
+
+	var opts replyOptions
+	for _, arg := range strings.Fields(args) {
+		if value, err := strconv.ParseFloat(arg, 64); err == nil {
+			opts.params.Temperature = &value
+			continue
+		}
+		if a.modelListModal.isUnavailable(chatProvider.Name(), arg) {
+			a.mainLayout.updateStatus(fmt.Sprintf("[red]🚫 %s is not a %s model", arg, chatProvider.Name()))
+			return
+		}
+		model = arg
+	}
+
+	a.mainLayout.inputField.SetText("")
+	a.regenerateLastReply(chatProvider, model, opts)
+}
+
+// transcribe turns the speech in an audio file into text in the
+// background and puts it in the input field, to be edited before it is
+// sent. Without a path the pending transcript is dropped.
+func (a *App) transcribe(path string) {
+	if path == "" {
+		a.transcription = nil
+		a.mainLayout.inputField.SetText("")
+		a.mainLayout.updateInputTitle()
+		a.mainLayout.updateStatus("[blue]🎙️  Transcript removed")
+		return
+	}
+
+	transcriber, name := a.transcriber()
+	if transcriber == nil {
+		a.mainLayout.updateStatus("[red]❌ No configured provider can transcribe audio - set GROQ_API_KEY")
+		return
+	}
+	if !slices.Contains(audioFormats, strings.ToLower(filepath.Ext(path))) {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ %s is not an audio file such as WAV, MP3 or M4A", filepath.Base(path)))
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ Failed to read audio: %v", err))
+		return
+	}
+	if info.Size() > maxAudioSize {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ %s is larger than %dMB", filepath.Base(path), maxAudioSize>>20))
+		return
+	}
+	audio, err := os.ReadFile(path)
+	if err != nil {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ Failed to read audio: %v", err))
+		return
+	}
+	source, err := filepath.Abs(path)
+	if err != nil {
+		source = path
+	}
+
+	fileName := filepath.Base(path)
+	language := a.settings.TranscriptionLanguage
+	a.mainLayout.updateStatus(fmt.Sprintf("[yellow]🎙️  Transcribing %s with %s...", fileName, name))
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), transcribeTimeout)
+		defer cancel()
+		result, err := transcriber.Transcribe(ctx, provider.TranscriptionRequest{
+			FileName: fileName,
+			Audio:    audio,
+			Language: language,
+		})
+
+		a.app.QueueUpdateDraw(func() {
+			if err != nil {
+				status := "[red]❌ Transcription failed: " + tview.Escape(err.Error())
+				var hinted provider.HintedError
+				if errors.As(err, &hinted) && hinted.Hint() != "" {
+					status += " 💡 " + tview.Escape(hinted.Hint())
+				}
+				a.mainLayout.updateStatus(status)
+				return
+			}
+			a.transcription = &storage.Transcription{Source: source, Model: result.Model, Text: result.Text}
+			a.mainLayout.inputField.SetText(result.Text)
+			a.mainLayout.updateInputTitle()
+			a.app.SetFocus(a.mainLayout.inputField)
+			a.mainLayout.updateStatus(fmt.Sprintf("[green]🎙️  Transcribed %s - edit the text and press Enter to send", fileName))
+		})
+	}()
+}
+
+// transcriber picks the provider that transcribes audio: the chat's own if
+// it can, otherwise the first configured one that can.
+func (a *App) transcriber() (provider.Transcriber, string) {
+	candidates := provider.All()
+	if current, err := provider.Get(a.currentSession.Provider); err == nil {
+		candidates = append([]provider.Provider{current}, candidates...)
+	}
+	for _, p := range candidates {
+		if transcriber, ok := p.(provider.Transcriber); ok && p.Configured() {
+			return transcriber, p.Name()
+		}
+	}
+	return nil, ""
+}
+
+// switchProfile moves the chat to another provider or endpoint profile, on
+// its default model. Without a name the available ones are listed.
+func (a *App) switchProfile(name string) bool {
+	if name == "" {
+		var names []string
+		for _, p := range provider.All() {
+			if p.Name() == a.currentSession.Provider {
+				names = append(names, "[green]"+p.Name()+"[blue]")
+			} else {
+				names = append(names, p.Name())
+			}
+		}
+		a.mainLayout.updateStatus("[blue]🔌 Profiles: " + strings.Join(names, ", ") + " - switch with /profile <name>")
+		return false
+	}
+
+	chatProvider, err := provider.Get(name)
+	if err != nil {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ Unknown profile: %s", name))
+		return false
+	}
+	if !chatProvider.Configured() {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ %s is not configured - set its API key first", name))
+		return false
+	}
+	a.setSessionModel(chatProvider.Name(), chatProvider.DefaultModel())
+	a.mainLayout.updateStatus(fmt.Sprintf("[green]🔌 Switched to %s", name))
+	return true
+}
+
+// describeChat asks a model for the chat's title and summary in the
+// background, once it has a first reply. The title cut from the first
+// message is kept when this is turned off or fails, e.g. offline.
+func (a *App) describeChat(session *storage.ChatSession) {
+	if !a.settings.AutoTitle || session.AutoTitled {
+		return
+	}
+
+	providerName := a.settings.TitleProvider
+	if providerName == "" {
+		providerName = session.Provider
+	}
+	chatProvider, err := provider.Get(providerName)
+	if err != nil || !chatProvider.Configured() {
+		return
+	}
+	model := a.settings.TitleModel
+	if model == "" {
+		model = a.modelListModal.cheapestModel(chatProvider)
+	}
+
+	// Marked up front so later replies don't ask again while this runs
+	session.AutoTitled = true
+	messages := history.Sendable(a.chatHistory)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), describeTimeout)
+		defer cancel()
+		description, err := titles.Generate(ctx, chatProvider, model, messages)
+
+		a.app.QueueUpdateDraw(func() {
+			if err != nil {
+				session.AutoTitled = false
+				return
+			}
+			session.Title = description.Title
+			session.Description = description.Summary
+			if a.currentSession == session {
+				a.mainLayout.updateSidebar()
+				a.saveCurrentChatInBackground()
+			} else {
+				session.Link()
+				go a.writeChat(session.Snapshot())
+			}
+		})
+	}()
+}
+
+// compare sends prompt to several models side by side, after they have
+// been picked in the compare modal.
+func (a *App) compare(prompt string) {
+	if a.activeRequest != nil {
+		a.mainLayout.updateStatus("[yellow]⏳ Still answering... press Esc to cancel")
+		return
+	}
+	if prompt == "" {
+		a.mainLayout.updateStatus("[yellow]Type /compare followed by the prompt to send")
+		return
+	}
+	a.compareModal.Show(prompt)
+}
+
+// cycleAlternative switches the last reply to its next or previous
+// regenerated version.
+func (a *App) cycleAlternative(delta int) {
+	if a.activeRequest != nil {
+		return
+	}
+	last := len(a.chatHistory) - 1
+	if last < 0 || a.chatHistory[last].Role != "assistant" {
+		a.mainLayout.updateStatus("[yellow]The last message is not a reply")
+		return
+	}
+
+	versions := a.chatHistory[last].Versions()
+	if len(versions) < 2 {
+		a.mainLayout.updateStatus("[yellow]Only one version of this reply - regenerate it with Ctrl+R")
+		return
+	}
+
+	index := (a.chatHistory[last].AltIndex + delta + len(versions)) % len(versions)
+	a.chatHistory[last] = a.chatHistory[last].WithVersion(index)
+	a.currentSession.Messages = a.chatHistory
+	a.mainLayout.updateConversationView()
+	a.mainLayout.updateSidebar()
+	a.mainLayout.updateStatus(fmt.Sprintf("[blue]🔁 Reply %d of %d", index+1, len(versions)))
+	a.saveCurrentChatInBackground()
+}
+
+// showRateLimit updates the status bar with the quota the provider reported
+// on its latest response, if it reports one.
+func (a *App) showRateLimit(chatProvider provider.Provider) {
+	limited, ok := chatProvider.(provider.RateLimited)
+	if !ok {
+		return
+	}
+	if limit, ok := limited.RateLimit(); ok {
+		a.mainLayout.updateRateLimit(chatProvider.Name(), limit)
+	}
+}
+
+// togglePin pins or unpins the selected message, so it survives trimming
+// with the keep-pinned context strategy.
+func (a *App) togglePin() {
+	index := a.mainLayout.selected
+	if index < 0 || index >= len(a.chatHistory) {
+		a.mainLayout.updateStatus("[yellow]Select a message with j/k first")
+		return
+	}
+	if a.chatHistory[index].Role == "error" {
+		a.mainLayout.updateStatus("[yellow]Errors are never sent, so they cannot be pinned")
+		return
+	}
+	if a.chatHistory[index].Role == "tool" || len(a.chatHistory[index].ToolCalls) > 0 {
+		a.mainLayout.updateStatus("[yellow]Tool calls are sent with their conversation, so they cannot be pinned")
+		return
+	}
+
+	a.chatHistory[index].Pinned = !a.chatHistory[index].Pinned
+	a.currentSession.Messages = a.chatHistory
+	a.mainLayout.updateConversationView()
+	if a.chatHistory[index].Pinned {
+		a.mainLayout.updateStatus("[green]📌 Message pinned")
+	} else {
+		a.mainLayout.updateStatus("[blue]📌 Message unpinned")
+	}
+	a.saveCurrentChatInBackground()
+}
+
+// editMessage puts the selected user message into the input field. Sending
+// it starts a new branch from that point, keeping the original one.
+func (a *App) editMessage() {
+	if a.activeRequest != nil {
+		a.mainLayout.updateStatus("[yellow]⏳ Still answering... press Esc to cancel")
+		return
+	}
+	index := a.mainLayout.selected
+	if index < 0 || index >= len(a.chatHistory) || a.chatHistory[index].Role != "user" {
+		a.mainLayout.updateStatus("[yellow]Select one of your messages with j/k first")
+		return
+	}
+
+	a.currentSession.Messages = a.chatHistory
+	a.currentSession.Link()
+	a.editing = a.chatHistory[index].ID
+	a.mainLayout.inputField.SetText(a.chatHistory[index].Content)
+	a.transcription = a.chatHistory[index].Transcription
+	a.setAttachments(slices.Clone(a.chatHistory[index].Attachments))
+	a.app.SetFocus(a.mainLayout.inputField)
+	a.mainLayout.updateStatus("[blue]✏️  Editing message - Enter sends it as a new branch, Esc cancels")
+}
+
+// cancelEdit stops editing an earlier message. It reports whether one was
+// being edited.
+func (a *App) cancelEdit() bool {
+	if a.editing == "" {
+		return false
+	}
+	a.editing = ""
+	a.mainLayout.inputField.SetText("")
+	a.transcription = nil
+	a.setAttachments(nil)
+	a.mainLayout.updateStatus("[blue]✏️  Edit cancelled")
+	return true
+}
+
+// forkAt moves the message with the given ID and everything after it into
+// a branch, so the edited message can be sent in its place.
+func (a *App) forkAt(id string) {
+	a.editing = ""
+	for i, msg := range a.chatHistory {
+		if msg.ID != id {
+			continue
+		}
+		a.currentSession.Messages = a.chatHistory
+		a.currentSession.Fork(i)
+		a.chatHistory = a.currentSession.Messages
+		// The summary covers the old branch's messages
+		a.currentSession.Summary = nil
+		a.mainLayout.clearSelection()
+		return
+	}
+}
+
+// switchBranch shows the previous or next branch starting at the selected
+// message.
+func (a *App) switchBranch(delta int) {
+	if a.activeRequest != nil {
+		a.mainLayout.updateStatus("[yellow]⏳ Still answering... press Esc to cancel")
+		return
+	}
+	index := a.mainLayout.selected
+	if index < 0 || index >= len(a.chatHistory) {
+		a.mainLayout.updateStatus("[yellow]Select a message with j/k first")
+		return
+	}
+
+	a.currentSession.Messages = a.chatHistory
+	siblings, position := a.currentSession.Siblings(index)
+	if len(siblings) < 2 {
+		a.mainLayout.updateStatus("[yellow]No other branch here - press e on one of your messages to edit it")
+		return
+	}
+
+	position = (position + delta + len(siblings)) % len(siblings)
+	if err := a.currentSession.SwitchBranch(index, siblings[position].ID); err != nil {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ %v", err))
+		return
+	}
+	a.chatHistory = a.currentSession.Messages
+	a.currentSession.Summary = nil
+	a.editing = ""
+	a.mainLayout.updateConversationView()
+	a.mainLayout.updateSidebar()
+	a.mainLayout.updateStatus(fmt.Sprintf("[blue]🌿 Branch %d of %d", position+1, len(siblings)))
+	a.saveCurrentChatInBackground()
+}
+
+// toggleReasoning expands or collapses the thinking of every reply.
+func (a *App) toggleReasoning() {
+	a.showReasoning = !a.showReasoning
+	a.mainLayout.updateConversationView()
+	if a.showReasoning {
+		a.mainLayout.updateStatus("[blue]💭 Showing reasoning - press t to collapse it")
+	} else {
+		a.mainLayout.updateStatus("[blue]💭 Reasoning collapsed")
+	}
+}
+
+// cancelGeneration aborts the in-flight request, if any. It reports whether
+// there was one to cancel.
+func (a *App) cancelGeneration() bool {
+	if a.activeRequest == nil {
+		return false
+	}
+	a.activeRequest.cancel()
+	a.activeRequest = nil
+	return true
+}
+
 func (a *App) clearChat() {
+	a.cancelGeneration()
 	a.chatHistory = []storage.ChatMessage{}
 	a.currentSession.Messages = a.chatHistory
+	a.currentSession.Branches = nil
+	a.currentSession.Summary = nil
+	a.editing = ""
+	a.mainLayout.clearSelection()
 	a.mainLayout.updateConversationView()
 	a.mainLayout.updateSidebar()
 	a.mainLayout.updateStatus("[blue]🧹 Chat cleared!")
@@ -77,12 +967,36 @@ func (a *App) clearChat() {
 	}()
 }
 
+// saveCurrentChat writes the current chat to disk. It runs on the UI
+// goroutine, as it links the messages before saving them.
 func (a *App) saveCurrentChat() {
+	if session := a.linkCurrentChat(); session != nil {
+		a.writeChat(session)
+	}
+}
+
+// saveCurrentChatInBackground links the current chat on the UI goroutine
+// and writes a snapshot of it to disk without blocking the interface.
+func (a *App) saveCurrentChatInBackground() {
+	if session := a.linkCurrentChat(); session != nil {
+		go a.writeChat(session.Snapshot())
+	}
+}
+
+// linkCurrentChat brings the current session up to date with the chat
+// history and gives its messages their IDs, or returns nil when there is
+// nothing to save.
+func (a *App) linkCurrentChat() *storage.ChatSession {
 	if len(a.chatHistory) == 0 {
-		return
+		return nil
 	}
 	a.currentSession.Messages = a.chatHistory
-	if err := a.storageManager.SaveChat(a.currentSession); err != nil {
+	a.currentSession.Link()
+	return a.currentSession
+}
+
+func (a *App) writeChat(session *storage.ChatSession) {
+	if err := a.storageManager.SaveChat(session); err != nil {
 		a.app.QueueUpdateDraw(func() {
 			a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ Save failed: %v", err))
 		})
@@ -7,6 +7,10 @@ import (
 	"github.com/rivo/tview"
 )
 
+// maxDescriptionDisplay caps the chat summary shown under each title, which
+// the list shows on a single line.
+const maxDescriptionDisplay = 120
+
 type ChatListModal struct {
 	app      *App
 	chatList *tview.List
@@ -109,6 +113,9 @@ func (clm *ChatListModal) Show() {
 			secondaryText := fmt.Sprintf("%d messages • Updated: %s",
 				summary.MessageCount,
 				summary.UpdatedAt.Format("Jan 2, 15:04"))
+			if summary.Description != "" {
+				secondaryText = truncateRunes(summary.Description, maxDescriptionDisplay)
+			}
 			clm.chatList.AddItem(mainText, secondaryText, 0, nil)
 		}
 	}
@@ -130,6 +137,7 @@ func (clm *ChatListModal) loadChatFromList(index int) {
 		return
 	}
 
+	clm.app.cancelGeneration()
 	clm.app.saveCurrentChat()
 	session, err := clm.app.storageManager.LoadChat(summaries[index].ID)
 	if err != nil {
@@ -139,6 +147,9 @@ func (clm *ChatListModal) loadChatFromList(index int) {
 
 	clm.app.SetCurrentSession(session)
 	clm.app.SetChatHistory(session.Messages)
+	clm.app.editing = ""
+	clm.app.mainLayout.clearSelection()
+	clm.app.refreshUsageTotals()
 	clm.app.mainLayout.updateConversationView()
 	clm.app.mainLayout.updateSidebar()
 	clm.app.mainLayout.updateStatus("[green]📂 Chat loaded successfully!")
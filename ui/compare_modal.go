@@ -0,0 +1,359 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/history"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/reasoning"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// compareMaxModels caps how many models one prompt is compared across, so
+// the columns stay readable.
+const compareMaxModels = 4
+
+// CompareModal sends one prompt to several models at once and shows their
+// replies side by side. One of them can then be kept in the chat.
+type CompareModal struct {
+	app       *App
+	layout    *tview.Flex
+	modelList *tview.List
+	footer    *tview.TextView
+
+	prompt  string
+	entries []modelEntry
+	chosen  map[string]bool // provider/model keys, kept between comparisons
+	batch   *compareBatch
+}
+
+// compareBatch is one prompt in flight to every chosen model.
+type compareBatch struct {
+	cancel  context.CancelFunc
+	session *storage.ChatSession
+	prompt  storage.ChatMessage
+	// editing is the ID of the message the prompt replaces, as when an
+	// edited message is sent, or empty when it follows the history
+	editing string
+	runs    []*compareRun
+}
+
+// compareRun is one model's reply within a batch.
+type compareRun struct {
+	chatProvider provider.Provider
+	model        string
+	title        string
+	view         *tview.TextView
+	reply        storage.ChatMessage
+	summary      *storage.ContextSummary
+	err          error
+	done         bool
+}
+
+func NewCompareModal(app *App) *CompareModal {
+	return &CompareModal{
+		app:       app,
+		modelList: tview.NewList(),
+		footer:    tview.NewTextView().SetDynamicColors(true),
+		chosen:    map[string]bool{},
+	}
+}
+
+func (cm *CompareModal) Create() *tview.Flex {
+	cm.modelList.ShowSecondaryText(true).
+		SetHighlightFullLine(true).
+		SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+			cm.start()
+		})
+	cm.modelList.SetBorder(true).SetTitle(" Compare Models ").SetBorderColor(tcell.ColorDarkCyan)
+	cm.modelList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			cm.Hide()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == ' ':
+			cm.toggle(cm.modelList.GetCurrentItem())
+			return nil
+		}
+		return event
+	})
+
+	cm.footer.SetBorder(true).SetBorderColor(tcell.ColorGreen)
+
+	cm.layout = tview.NewFlex().SetDirection(tview.FlexRow)
+	cm.layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if cm.batch == nil {
+			return event
+		}
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			cm.Hide()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() >= '1' && event.Rune() <= '9':
+			cm.promote(int(event.Rune() - '1'))
+			return nil
+		}
+		return event
+	})
+
+	return cm.layout
+}
+
+// Show lists the known models so the ones to compare can be picked. The
+// prompt is only sent once the choice is made.
+func (cm *CompareModal) Show(prompt string) {
+	cm.prompt = prompt
+	cm.entries = nil
+	images := hasImages(cm.app.chatHistory) || len(cm.app.attachments) > 0
+	for _, entry := range cm.app.modelListModal.entries {
+		p, err := provider.Get(entry.provider)
+		if err != nil || !p.Configured() || entry.model.Unavailable {
+			continue
+		}
+		if images && !cm.app.modelListModal.supportsVision(entry.provider, entry.model.ID) {
+			continue
+		}
+		cm.entries = append(cm.entries, entry)
+	}
+	if len(cm.entries) < 2 {
+		cm.app.mainLayout.updateStatus("[yellow]Comparing needs at least two available models - check Ctrl+- once they have loaded")
+		return
+	}
+
+	cm.populate()
+	cm.footer.SetText("[white]Space picks a model (up to 4) • Enter sends the prompt to them • Esc closes")
+	cm.layout.Clear().
+		AddItem(cm.modelList, 0, 1, true).
+		AddItem(cm.footer, 3, 1, false)
+
+	cm.app.pages.ShowPage("compare")
+	cm.app.isShowingCompare = true
+	cm.app.app.SetFocus(cm.modelList)
+}
+
+// Hide closes the modal, stopping any replies still streaming.
+func (cm *CompareModal) Hide() {
+	if cm.batch != nil {
+		cm.batch.cancel()
+		cm.batch = nil
+	}
+	cm.app.pages.HidePage("compare")
+	cm.app.isShowingCompare = false
+	cm.app.app.SetFocus(cm.app.mainLayout.inputField)
+}
+
+func (cm *CompareModal) populate() {
+	current := cm.modelList.GetCurrentItem()
+	cm.modelList.Clear()
+	for _, entry := range cm.entries {
+		mark := "☐ "
+		if cm.chosen[compareKey(entry)] {
+			mark = "☑ "
+		}
+		cm.modelList.AddItem(mark+entry.model.Name, fmt.Sprintf("%s • %s", entry.provider, entry.model.ID), 0, nil)
+	}
+	if current < cm.modelList.GetItemCount() {
+		cm.modelList.SetCurrentItem(current)
+	}
+}
+
+func (cm *CompareModal) toggle(index int) {
+	if index < 0 || index >= len(cm.entries) {
+		return
+	}
+	key := compareKey(cm.entries[index])
+	if !cm.chosen[key] && len(cm.picked()) >= compareMaxModels {
+		cm.footer.SetText(fmt.Sprintf("[yellow]At most %d models can be compared at once", compareMaxModels))
+		return
+	}
+	cm.chosen[key] = !cm.chosen[key]
+	cm.populate()
+	cm.footer.SetText(fmt.Sprintf("[white]%d picked • Enter sends the prompt to them • Esc closes", len(cm.picked())))
+}
+
+// picked returns the chosen models that are still listed.
+func (cm *CompareModal) picked() []modelEntry {
+	var picked []modelEntry
+	for _, entry := range cm.entries {
+		if cm.chosen[compareKey(entry)] {
+			picked = append(picked, entry)
+		}
+	}
+	return picked
+}
+
+func compareKey(entry modelEntry) string {
+	return entry.provider + "/" + entry.model.ID
+}
+
+// start sends the prompt, with the pending attachments, after the current
+// history to every picked model at once and streams each reply into its
+// own column.
+func (cm *CompareModal) start() {
+	picked := cm.picked()
+	if len(picked) < 2 {
+		cm.footer.SetText("[yellow]Pick at least two models with Space first")
+		return
+	}
+
+	a := cm.app
+	ctx, cancel := context.WithCancel(context.Background())
+	batch := &compareBatch{
+		cancel:  cancel,
+		session: a.currentSession,
+		prompt: storage.ChatMessage{
+			Role:          "user",
+			Content:       cm.prompt,
+			Timestamp:     time.Now(),
+			Attachments:   a.attachments,
+			Transcription: a.transcription,
+		},
+		editing: a.editing,
+	}
+	cm.batch = batch
+
+	// An edited message replaces itself and everything after it, and the
+	// summary of the old branch does not apply
+	base, summary := a.chatHistory, batch.session.Summary
+	if batch.editing != "" {
+		if i := slices.IndexFunc(base, func(msg storage.ChatMessage) bool { return msg.ID == batch.editing }); i >= 0 {
+			base = base[:i]
+		}
+		summary = nil
+	}
+
+	columns := tview.NewFlex().SetDirection(tview.FlexColumn)
+	for i, entry := range picked {
+		chatProvider, _ := provider.Get(entry.provider)
+		title := fmt.Sprintf("%d • %s", i+1, entry.model.Name)
+		view := tview.NewTextView().SetDynamicColors(true).SetWrap(true).SetWordWrap(true)
+		view.SetBorder(true).SetTitle(" " + title + " ").SetBorderColor(tcell.ColorDarkCyan)
+		view.SetText("[yellow]🤔 Thinking...")
+		run := &compareRun{chatProvider: chatProvider, model: entry.model.ID, title: title, view: view}
+		batch.runs = append(batch.runs, run)
+		columns.AddItem(view, 0, 1, false)
+	}
+
+	cm.footer.SetText(fmt.Sprintf("[white]Press 1-%d to keep that reply in the chat • Esc closes", len(batch.runs)))
+	cm.layout.Clear().
+		AddItem(columns, 0, 1, false).
+		AddItem(cm.footer, 3, 1, false)
+	a.app.SetFocus(cm.layout)
+
+	sendable := history.Sendable(append(base[:len(base):len(base)], batch.prompt))
+	params := a.generationParams()
+	for _, run := range batch.runs {
+		window := contextWindow{
+			messages: sendable,
+			budget:   a.contextBudget(run.chatProvider.Name(), run.model, params, false, false),
+			strategy: a.contextStrategy(),
+		}
+		if summary != nil {
+			window.summary = *summary
+		}
+		go cm.run(ctx, batch, run, window, params)
+	}
+}
+
+func (cm *CompareModal) run(ctx context.Context, batch *compareBatch, run *compareRun, window contextWindow, params storage.GenerationParams) {
+	a := cm.app
+	onDelta := func(delta string) {
+		a.app.QueueUpdateDraw(func() {
+			if cm.batch != batch {
+				return
+			}
+			run.reply.Content += delta
+			run.view.SetText(tview.Escape(run.reply.Content))
+			run.view.ScrollToEnd()
+		})
+	}
+
+	started := time.Now()
+	messages, stats := a.fitContext(ctx, run.chatProvider, run.model, &window)
+	if batch.session.SystemPrompt != "" {
+		messages = append([]storage.ChatMessage{{Role: "system", Content: batch.session.SystemPrompt}}, messages...)
+	}
+
+	chatRequest := provider.ChatRequest{Model: run.model, Messages: messages, Params: params}
+	var resp *provider.ChatResponse
+	var err error
+	if run.chatProvider.Capabilities().Streaming {
+		resp, err = run.chatProvider.Stream(ctx, chatRequest, onDelta)
+	} else {
+		resp, err = run.chatProvider.Chat(ctx, chatRequest)
+	}
+	latency := time.Since(started)
+
+	a.app.QueueUpdateDraw(func() {
+		if cm.batch != batch {
+			return
+		}
+		run.done = true
+		run.err = err
+		if window.summary.Content != "" {
+			summary := window.summary
+			run.summary = &summary
+			if batch.editing == "" {
+				batch.session.Summary = &summary
+			}
+		}
+		if err != nil {
+			run.view.SetText(fmt.Sprintf("%s\n\n[red]❌ %s", tview.Escape(run.reply.Content), tview.Escape(err.Error())))
+			return
+		}
+		reply, thinking := reasoning.SplitFor(run.model, resp.Content)
+		run.reply = storage.ChatMessage{
+			Role:      "assistant",
+			Content:   reply,
+			Reasoning: reasoning.Join(resp.Reasoning, thinking),
+			Timestamp: time.Now(),
+			Model:     run.model,
+			Usage:     resp.Usage,
+			LatencyMs: latency.Milliseconds(),
+			Params:    usedParams(params),
+			Context:   stats,
+		}
+		run.view.SetText(tview.Escape(run.reply.Content))
+		run.view.SetTitle(fmt.Sprintf(" %s • %s ", run.title, replyDetails(run.reply)))
+		run.view.SetBorderColor(tcell.ColorGreen)
+	})
+}
+
+// promote adds the prompt and the reply in column index to the chat, as if
+// it had been sent to that model alone.
+func (cm *CompareModal) promote(index int) {
+	batch := cm.batch
+	if index < 0 || index >= len(batch.runs) {
+		return
+	}
+	run := batch.runs[index]
+	if !run.done || run.err != nil {
+		cm.footer.SetText(fmt.Sprintf("[yellow]Reply %d is not ready to keep", index+1))
+		return
+	}
+
+	a := cm.app
+	cm.Hide()
+	if a.currentSession != batch.session {
+		return
+	}
+	if batch.editing != "" {
+		a.forkAt(batch.editing)
+		a.currentSession.Summary = run.summary
+	}
+	a.chatHistory = append(a.chatHistory, batch.prompt, run.reply)
+	a.currentSession.Messages = a.chatHistory
+	a.currentSession.Link()
+	a.mainLayout.inputField.SetText("")
+	a.transcription = nil
+	a.setAttachments(nil)
+	a.mainLayout.updateConversationView()
+	a.mainLayout.updateSidebar()
+	a.mainLayout.updateStatus(fmt.Sprintf("[green]✅ Kept the reply from %s", a.modelListModal.displayName(run.chatProvider.Name(), run.model)))
+	a.saveCurrentChatInBackground()
+}
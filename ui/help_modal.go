@@ -18,26 +18,103 @@ func (hm *HelpModal) Create() *tview.Modal {
 
 📋 Key Bindings:
 • Enter        - Send message
+• Esc/Ctrl+X   - Cancel the reply in progress
+• Ctrl+R       - Retry an error or regenerate the last reply
 • Ctrl+C       - Quit application (auto-saves)
 • Ctrl+H       - Show/hide this help
 • Ctrl+L       - Clear conversation
-• Ctrl+N       - Start new chat
+• Ctrl+N       - Start new chat (pick a persona)
 • Ctrl+S       - Save current chat
 • Ctrl+O       - Open chat history
 • Ctrl+-       - Switch AI models
-• Tab          - Navigate between elements
+• Ctrl+P       - Generation settings (temperature, seed...)
 • Shift+Tab    - Navigate backwards
 • Ctrl+U       - Clear input field
+• Tab          - Move between input and messages
+• j/k          - Select a message (in the conversation)
+• p            - Pin/unpin the selected message
+• < / >        - Switch between versions of the last reply
+• e            - Edit the selected message into a new branch
+• h / l        - Switch branch at the selected message
+• t            - Show/hide the reasoning of replies
 
 🤖 AI Models:
-• Switch between different Groq models
-• Default: Llama 3 70B for best performance
+• Switch between models from every configured provider
+• Default: Llama 3.3 70B on Groq for best performance
 • Use Ctrl+- to change models anytime
+• Each chat remembers its provider and model
+
+🎭 Personas & System Prompts:
+• /system <text> sets the chat's system prompt
+• /system on its own clears it
+• Personas in personas.json are offered on Ctrl+N
+• A chat keeps its persona when reopened
+
+🔧 Tools:
+• /tools lets the model call a calculator, the clock and,
+  with TUI_GPT_FILES_DIR set, read files from that folder
+• Tool calls and results appear in the conversation
+
+🧾 JSON Replies:
+• /json toggles JSON-only replies for the chat
+• /schema <file> checks replies against a JSON Schema
+  and asks again when they don't match
+
+🔁 Regenerating:
+• Ctrl+R asks for a new version of the last reply
+• /regen [model] [temperature] does the same with a
+  different model or temperature, for that reply only
+• Earlier versions are kept; switch with < and >
+
+🖼️ Images:
+• /image <path> attaches an image to your next message;
+  /image alone removes the attachments
+• Only models marked 🖼️ vision in Ctrl+- get images
+
+💭 Reasoning:
+• The thinking of reasoning models is collapsed;
+  press t in the conversation to show or hide it
+• Only the answer is sent back on later turns
+
+🎙️ Audio Input:
+• /audio <path> transcribes a recording into the input
+  field; edit it and press Enter to send
+• /audio alone discards the transcript
+
+🧭 Endpoint Profiles:
+• OpenAI-compatible servers defined in profiles.json
+  appear in the model picker
+• /profile lists them; /profile <name> moves the chat
+  to one, on its default model
+
+⚖️ Comparing Models:
+• /compare <prompt> sends the prompt to up to 4 models
+  at once; pick them with Space and press Enter
+• Replies stream side by side with latency and tokens
+• Press 1-4 to keep one of them in the chat
+
+🌿 Branches:
+• Select one of your messages, press e, change it and
+  press Enter to continue from there on a new branch
+• The original conversation is kept; h and l switch
+  between branches at the selected message
+
+✂️ Truncated Replies:
+• Replies cut off by max_tokens are marked truncated
+• Tick "Continue cut-off replies" in Ctrl+P to have the
+  rest fetched and merged into the same reply
+
+📏 Long Chats:
+• History is trimmed to fit the model's context window
+• Choose to drop, keep pinned or summarize old turns (Ctrl+P)
+• Replies show how many messages were sent
 
 💾 Chat Storage:
 • Chats are automatically saved locally
 • Access previous chats with Ctrl+O
 • Each chat gets a title from first message
+• Tick "Model-written titles" in Ctrl+P for titles and
+  summaries written by a model
 
 💡 Tips:
 • Type your message and press Enter
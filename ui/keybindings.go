@@ -1,6 +1,9 @@
 package ui
 
 import (
+	"fmt"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/config"
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -13,40 +16,59 @@ func (a *App) setupKeyBindings() {
 		case tcell.KeyCtrlU:
 			a.mainLayout.inputField.SetText("")
 			return nil
+		case tcell.KeyTab:
+			a.app.SetFocus(a.mainLayout.conversationView)
+			a.mainLayout.updateStatus("[blue]💬 j/k to select a message • Tab to type")
+			return nil
 		}
 		return event
 	})
 
 	a.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
+		case tcell.KeyEscape, tcell.KeyCtrlX:
+			if !a.isShowingModal() && (a.cancelGeneration() || a.cancelEdit()) {
+				return nil
+			}
+			return event
 		case tcell.KeyCtrlH:
-			if !a.isShowingChatList && !a.isShowingModelList {
+			if !a.isShowingModal() {
 				a.toggleHelp()
 			}
 			return nil
 		case tcell.KeyCtrlL:
-			if !a.isShowingChatList && !a.isShowingModelList {
+			if !a.isShowingModal() {
 				a.clearChat()
 			}
 			return nil
 		case tcell.KeyCtrlN:
-			if !a.isShowingChatList && !a.isShowingModelList {
+			if !a.isShowingModal() {
 				a.newChat()
 			}
 			return nil
 		case tcell.KeyCtrlS:
-			if !a.isShowingChatList && !a.isShowingModelList {
+			if !a.isShowingModal() {
 				a.saveCurrentChat()
 				a.mainLayout.updateStatus("[green]💾 Chat saved!")
 			}
 			return nil
 		case tcell.KeyCtrlO:
-			if !a.isShowingChatList && !a.isShowingModelList {
+			if !a.isShowingModal() {
 				a.chatListModal.Show()
 			}
 			return nil
+		case tcell.KeyCtrlR:
+			if !a.isShowingModal() {
+				a.retryOrRegenerate()
+			}
+			return nil
+		case tcell.KeyCtrlP:
+			if !a.isShowingModal() {
+				a.settingsModal.Show()
+			}
+			return nil
 		case tcell.KeyCtrlUnderscore:
-			if !a.isShowingChatList && !a.isShowingModelList {
+			if !a.isShowingModal() {
 				a.modelListModal.Show()
 			}
 			return nil
@@ -66,10 +88,36 @@ func (a *App) toggleHelp() {
 	}
 }
 
+// newChat starts a fresh chat, first offering the personas from
+// personas.json when there are any.
 func (a *App) newChat() {
+	personas, err := config.LoadPersonas()
+	if err != nil {
+		a.mainLayout.updateStatus(fmt.Sprintf("[red]❌ Failed to load personas: %v", err))
+	}
+	if len(personas) > 0 {
+		a.personaModal.Show(personas)
+		return
+	}
+	a.startChat(nil)
+}
+
+// startChat saves the current chat and starts a new one, set up from the
+// persona if one is given.
+func (a *App) startChat(persona *config.Persona) {
+	a.cancelGeneration()
 	a.saveCurrentChat()
 	a.startNewChat()
+	a.mainLayout.clearSelection()
+	if persona != nil {
+		a.applyPersona(*persona)
+	}
+	a.refreshUsageTotals()
 	a.mainLayout.updateConversationView()
 	a.mainLayout.updateSidebar()
-	a.mainLayout.updateStatus("[blue]🆕 Started new chat!")
+	if persona != nil {
+		a.mainLayout.updateStatus(fmt.Sprintf("[blue]🎭 Started new chat as %s!", persona.Name))
+	} else {
+		a.mainLayout.updateStatus("[blue]🆕 Started new chat!")
+	}
 }
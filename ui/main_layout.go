@@ -1,13 +1,19 @@
 package ui
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
 	"unicode/utf8" // Import for accurate character counting
 
-	"github.com/Rohan-Shah-312003/tui-gpt/internal/groq"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/jsonmode"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/reasoning"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
 	"github.com/gdamore/tcell/v2"
 
 	"github.com/rivo/tview"
@@ -20,11 +26,16 @@ type MainLayout struct {
 	statusBar        *tview.TextView
 	sidebar          *tview.TextView
 	// messageContainer *tview.Flex // Removed: No longer needed for individual bubbles
+
+	status   string // last message passed to updateStatus
+	quota    string // rate-limit counters shown after the status
+	selected int    // index of the selected message, or -1
 }
 
 func NewMainLayout(app *App) *MainLayout {
 	return &MainLayout{
-		app: app,
+		app:      app,
+		selected: -1,
 	}
 }
 
@@ -117,15 +128,52 @@ func (ml *MainLayout) createConversationView() *tview.TextView {
 			row, col := ml.conversationView.GetScrollOffset()
 			ml.conversationView.ScrollTo(row+1, col)
 			return nil
+		case tcell.KeyEscape:
+			if ml.selected >= 0 {
+				ml.clearSelection()
+				ml.updateConversationView()
+				return nil
+			}
+		case tcell.KeyTab:
+			ml.app.app.SetFocus(ml.inputField)
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'k':
+				ml.moveSelection(-1)
+				return nil
+			case 'j':
+				ml.moveSelection(1)
+				return nil
+			case 'p':
+				ml.app.togglePin()
+				return nil
+			case '<':
+				ml.app.cycleAlternative(-1)
+				return nil
+			case '>':
+				ml.app.cycleAlternative(1)
+				return nil
+			case 'e':
+				ml.app.editMessage()
+				return nil
+			case 'h':
+				ml.app.switchBranch(-1)
+				return nil
+			case 'l':
+				ml.app.switchBranch(1)
+				return nil
+			case 't':
+				ml.app.toggleReasoning()
+				return nil
+			}
 		}
 		return event
 	})
 
 	// Set a changed func to ensure it always scrolls to end when new content is added
 	conversationView.SetChangedFunc(func() {
-		ml.app.app.QueueUpdateDraw(func() {
-			ml.conversationView.ScrollToEnd()
-		})
+		ml.app.app.QueueUpdateDraw(ml.scrollToFocus)
 	})
 
 	return conversationView
@@ -147,6 +195,19 @@ func (ml *MainLayout) createInputField() *tview.InputField {
 	return inputField
 }
 
+// updateInputTitle lists the files attached to the message being written,
+// and the recording it was transcribed from, in the input's title.
+func (ml *MainLayout) updateInputTitle() {
+	title := " ✍️ Your Input "
+	if transcription := ml.app.transcription; transcription != nil {
+		title += "• 🎙️ " + tview.Escape(filepath.Base(transcription.Source)) + " "
+	}
+	for _, attachment := range ml.app.attachments {
+		title += "• 📎 " + tview.Escape(attachmentLabel(attachment)) + " "
+	}
+	ml.inputField.SetTitle(title)
+}
+
 func (ml *MainLayout) createButtonFlex() *tview.Flex {
 	buttonFlex := tview.NewFlex().SetDirection(tview.FlexColumn)
 
@@ -257,7 +318,19 @@ func (ml *MainLayout) formatCodeBlocks(text string) string {
 
 // formatChatMessage prepares a message for display as plain text with role, content, and timestamp.
 // No "bubbles" (solid background blocks) are created.
-func (ml *MainLayout) formatChatMessage(role, content, timestamp string) string {
+func (ml *MainLayout) formatChatMessage(msg storage.ChatMessage) string {
+	role, content := msg.Role, msg.Content
+	timestamp := msg.Timestamp.Format("15:04")
+
+	// Thinking still in the content, as while a reply streams, is shown
+	// apart from the answer like stored reasoning
+	thinking := msg.Reasoning
+	if role == "assistant" {
+		var inline string
+		content, inline = reasoning.SplitFor(msg.Model, content)
+		thinking = reasoning.Join(thinking, inline)
+	}
+
 	var rolePrefix, contentFgColor, timestampFgColor string
 
 	switch role {
@@ -273,6 +346,11 @@ func (ml *MainLayout) formatChatMessage(role, content, timestamp string) string
 		rolePrefix = "Error:"
 		contentFgColor = "red"
 		timestampFgColor = "darkred"
+	case "tool":
+		rolePrefix = "🔧 Result:"
+		contentFgColor = "teal"
+		timestampFgColor = "darkgray"
+		content = truncateRunes(content, maxToolResultDisplay)
 	default:
 		rolePrefix = "System:"
 		contentFgColor = "yellow"
@@ -292,19 +370,62 @@ func (ml *MainLayout) formatChatMessage(role, content, timestamp string) string
 	// and to ensure it doesn't interfere with word wrapping of the main content.
 	// timestampFormatted := fmt.Sprintf("[%s]%s[::-]", timestampFgColor, timestamp)
 
-	// Main content with its color
+	// Main content with its color. JSON replies are pretty-printed as they
+	// are, since markdown formatting would mangle them
 	formattedContent := ml.formatCodeBlocks(content) // Apply code block formatting here
+	if pretty, ok := prettyJSON(content); ok && role == "assistant" {
+		formattedContent = "\n" + tview.Escape(pretty)
+	}
+
+	if thinking != "" {
+		messageBuilder.WriteString("\n" + ml.reasoningSection(thinking))
+	}
 
 	// Add content
 	messageBuilder.WriteString(fmt.Sprintf(" [%s]%s[::-]\n", contentFgColor, formattedContent))
 
+	for _, call := range msg.ToolCalls {
+		messageBuilder.WriteString(fmt.Sprintf("[teal]🔧 %s(%s)[::-]\n", tview.Escape(call.Name), tview.Escape(call.Arguments)))
+	}
+
+	for _, attachment := range msg.Attachments {
+		messageBuilder.WriteString(fmt.Sprintf("[aqua]%s[::-]\n", tview.Escape("["+attachmentLabel(attachment)+"]")))
+	}
+
+	if msg.Transcription != nil {
+		messageBuilder.WriteString(fmt.Sprintf("[darkgray]🎙️ transcribed from %s[::-]\n", tview.Escape(filepath.Base(msg.Transcription.Source))))
+	}
+
+	if msg.Pinned {
+		messageBuilder.WriteString("[black:teal] 📌 pinned [white:black]\n")
+	}
+
+	if msg.Cancelled {
+		messageBuilder.WriteString("[black:yellow] cancelled [white:black]\n")
+	}
+
+	if msg.FinishReason == provider.FinishLength {
+		messageBuilder.WriteString("[black:orange] truncated [white:black] [darkgray]cut off by max_tokens[::-]\n")
+	}
+
+	if versions := msg.Versions(); len(versions) > 1 {
+		messageBuilder.WriteString(fmt.Sprintf("[darkgray]◀ %d/%d ▶ (< > to switch)[::-]\n", msg.AltIndex+1, len(versions)))
+	}
+
+	if role == "error" && msg.Hint != "" {
+		messageBuilder.WriteString(fmt.Sprintf("[yellow]💡 %s[::-]\n", tview.Escape(msg.Hint)))
+	}
+
 	// Add timestamp on a new line, right-aligned within the *available space*
 	// This will not have a solid background, just text color.
 	_, _, viewWidth, _ := ml.conversationView.GetRect()
 	effectiveWidth := viewWidth - 4 // Account for conversationView borders
 
 	timestampText := fmt.Sprintf("[%s]%s[::-]", timestampFgColor, timestamp)
-	timestampPadded := strings.Repeat(" ", effectiveWidth-runeCountInString(timestampText)) + timestampText
+	if details := replyDetails(msg); details != "" {
+		timestampText = fmt.Sprintf("[darkgray]%s • [::-]", details) + timestampText
+	}
+	timestampPadded := strings.Repeat(" ", max(effectiveWidth-runeCountInString(timestampText), 0)) + timestampText
 	messageBuilder.WriteString(timestampPadded)
 
 	messageBuilder.WriteString("\n") // Newline after message for next one or spacing
@@ -312,6 +433,88 @@ func (ml *MainLayout) formatChatMessage(role, content, timestamp string) string
 	return messageBuilder.String()
 }
 
+// reasoningSection renders a reasoning model's thinking, collapsed to a
+// one-line summary unless it has been expanded with t.
+func (ml *MainLayout) reasoningSection(thinking string) string {
+	words := len(strings.Fields(thinking))
+	if !ml.app.showReasoning {
+		return fmt.Sprintf("[darkgray]💭 Reasoning • %d words (t to show)[::-]\n", words)
+	}
+	return fmt.Sprintf("[darkgray]💭 Reasoning • %d words (t to hide)\n%s[::-]\n", words, tview.Escape(thinking))
+}
+
+// prettyJSON indents content if it is a JSON object or array, possibly
+// wrapped in a code fence.
+func prettyJSON(content string) (string, bool) {
+	document := jsonmode.Extract(content)
+	if !strings.HasPrefix(document, "{") && !strings.HasPrefix(document, "[") {
+		return "", false
+	}
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, []byte(document), "", "  "); err != nil {
+		return "", false
+	}
+	return pretty.String(), true
+}
+
+// maxToolResultDisplay caps how much of a tool result is shown; the model
+// still receives all of it.
+const maxToolResultDisplay = 300
+
+// truncateRunes shortens s to at most limit runes, marking the cut.
+func truncateRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit]) + "…"
+}
+
+// attachmentLabel describes an attachment by kind, name and size, e.g.
+// "image: diagram.png 240KB".
+func attachmentLabel(attachment storage.Attachment) string {
+	kind := "file"
+	if attachment.IsImage() {
+		kind = "image"
+	}
+	return fmt.Sprintf("%s: %s %s", kind, attachment.Name, formatSize(len(attachment.Data)))
+}
+
+// formatSize renders a byte count in B, KB or MB.
+func formatSize(bytes int) string {
+	switch {
+	case bytes < 1024:
+		return fmt.Sprintf("%dB", bytes)
+	case bytes < 1024*1024:
+		return fmt.Sprintf("%dKB", bytes/1024)
+	}
+	return fmt.Sprintf("%.1fMB", float64(bytes)/(1024*1024))
+}
+
+// replyDetails summarizes the model, token usage, latency, parameters and
+// trimmed context recorded on an assistant reply.
+func replyDetails(msg storage.ChatMessage) string {
+	var parts []string
+	if msg.Model != "" {
+		parts = append(parts, msg.Model)
+	}
+	if msg.Usage != nil {
+		parts = append(parts, fmt.Sprintf("%d+%d tok", msg.Usage.PromptTokens, msg.Usage.CompletionTokens))
+	}
+	if msg.LatencyMs > 0 {
+		parts = append(parts, fmt.Sprintf("%.1fs", float64(msg.LatencyMs)/1000))
+	}
+	if params := paramsSummary(msg.Params); params != "" {
+		parts = append(parts, params)
+	}
+	if msg.Context != nil {
+		parts = append(parts, fmt.Sprintf("sent %d/%d msgs", msg.Context.Sent, msg.Context.Total))
+	}
+	if msg.Continued > 0 {
+		parts = append(parts, fmt.Sprintf("continued %d×", msg.Continued))
+	}
+	return strings.Join(parts, " • ")
+}
+
 func (ml *MainLayout) updateConversationView() {
 	chatHistory := ml.app.GetChatHistory()
 
@@ -321,6 +524,10 @@ func (ml *MainLayout) updateConversationView() {
 	// Build the conversation text
 	var conversation strings.Builder
 
+	if session := ml.app.GetCurrentSession(); session != nil && session.SystemPrompt != "" {
+		conversation.WriteString(fmt.Sprintf("\n[yellow::b]🧭 System:[::-] [yellow]%s[::-]\n", tview.Escape(session.SystemPrompt)))
+	}
+
 	if len(chatHistory) == 0 {
 		// Welcome message - simplified to plain text, no full backgrounds
 		welcomeMsg := `
@@ -332,17 +539,85 @@ func (ml *MainLayout) updateConversationView() {
 `
 		conversation.WriteString(welcomeMsg)
 	} else {
-		// Add chat messages as formatted text lines
-		for _, msg := range chatHistory {
-			timestamp := msg.Timestamp.Format("15:04")
-			formattedMessage := ml.formatChatMessage(msg.Role, msg.Content, timestamp)
-			conversation.WriteString(formattedMessage)
+		// Add chat messages as formatted text lines, each in its own region
+		// so it can be selected
+		session := ml.app.GetCurrentSession()
+		for i, msg := range chatHistory {
+			formattedMessage := ml.formatChatMessage(msg)
+			if session != nil && len(session.Branches) > 0 {
+				if siblings, position := storage.Siblings(msg, session.Branches); len(siblings) > 1 {
+					formattedMessage = fmt.Sprintf("[darkgray]🌿 branch %d/%d (h l to switch)[::-]\n", position+1, len(siblings)) + formattedMessage
+				}
+			}
+			conversation.WriteString(fmt.Sprintf(`["%s"]%s[""]`, messageRegion(i), formattedMessage))
+		}
+
+		// Only the latest failure can be retried
+		if chatHistory[len(chatHistory)-1].Role == "error" {
+			conversation.WriteString("[darkgray]🔁 Press Ctrl+R to retry[::-]\n")
 		}
 	}
 
-	// Set the text and scroll to the bottom
+	if ml.selected >= len(chatHistory) {
+		ml.selected = -1
+	}
+
+	// Set the text and scroll to the bottom, or to the selected message
 	ml.conversationView.SetText(conversation.String())
-	ml.conversationView.ScrollToEnd()
+	if ml.selected >= 0 {
+		ml.conversationView.Highlight(messageRegion(ml.selected))
+	} else {
+		ml.conversationView.Highlight()
+	}
+	ml.scrollToFocus()
+}
+
+func messageRegion(index int) string {
+	return fmt.Sprintf("msg-%d", index)
+}
+
+// scrollToFocus keeps the selected message in view, or follows the end of
+// the conversation when nothing is selected.
+func (ml *MainLayout) scrollToFocus() {
+	if ml.selected >= 0 {
+		ml.conversationView.ScrollToHighlight()
+	} else {
+		ml.conversationView.ScrollToEnd()
+	}
+}
+
+// moveSelection selects the message delta places away from the current
+// one. With nothing selected, it starts from the latest message.
+func (ml *MainLayout) moveSelection(delta int) {
+	count := len(ml.app.GetChatHistory())
+	if count == 0 {
+		return
+	}
+
+	if ml.selected < 0 {
+		ml.selected = count - 1
+	} else {
+		ml.selected = min(max(ml.selected+delta, 0), count-1)
+	}
+	ml.updateConversationView()
+	ml.updateStatus(fmt.Sprintf("[blue]👉 Message %d of %d selected • p to pin • Esc to deselect", ml.selected+1, count))
+}
+
+func (ml *MainLayout) clearSelection() {
+	ml.selected = -1
+}
+
+// appendToMessage appends a streamed delta to the message at index and
+// redraws the conversation. It reports whether the message was updated.
+func (ml *MainLayout) appendToMessage(index int, delta string) bool {
+	chatHistory := ml.app.GetChatHistory()
+	if index < 0 || index >= len(chatHistory) {
+		return false
+	}
+
+	chatHistory[index].Content += delta
+	ml.updateConversationView()
+	return true
 }
 
 func (ml *MainLayout) updateSidebar() {
@@ -355,8 +630,10 @@ func (ml *MainLayout) updateSidebar() {
 
 	userCount, aiCount, errorCount := 0, 0, 0
 	totalChars := 0
+	var sessionUsage storage.Usage
 	for _, msg := range chatHistory {
-		totalChars += len(msg.Content)
+		totalChars += utf8.RuneCountInString(msg.Content)
+		sessionUsage.Add(msg.TotalUsage())
 		switch msg.Role {
 		case "user":
 			userCount++
@@ -374,16 +651,45 @@ func (ml *MainLayout) updateSidebar() {
 	}
 	content.WriteString(fmt.Sprintf("[yellow][white] 📝 Characters: %-4d[yellow][white]\n", totalChars))
 
+	// Token Usage
+	allTime := ml.app.pastUsage
+	allTime.Add(sessionUsage)
+	content.WriteString("[green] 🔢 TOKENS [white]\n")
+	content.WriteString(fmt.Sprintf("[green][white] Session: %-8d[green][white]\n", sessionUsage.TotalTokens))
+	content.WriteString(fmt.Sprintf("[green][white]  in %d • out %d[green][white]\n", sessionUsage.PromptTokens, sessionUsage.CompletionTokens))
+	content.WriteString(fmt.Sprintf("[green][white] All-time: %-7d[green][white]\n", allTime.TotalTokens))
+	content.WriteString("\n")
+
 	// Current Model Info
-	currentModel := groq.GetCurrentModel()
-	models := groq.GetAvailableModels()
-	if modelName, exists := models[currentModel]; exists {
+	if currentSession != nil && currentSession.Model != "" {
+		modelName := ml.app.modelListModal.displayName(currentSession.Provider, currentSession.Model)
 		content.WriteString("[cyan] 🤖 MODEL [white]\n")
 		modelDisplayName := strings.Replace(modelName, "Meta ", "", 1)
 		if len(modelDisplayName) > 15 {
 			modelDisplayName = modelDisplayName[:15] + "..."
 		}
 		content.WriteString(fmt.Sprintf("[cyan][white] %-15s[cyan][white]\n", modelDisplayName))
+		content.WriteString(fmt.Sprintf("[cyan][white] via %-11s[cyan][white]\n", currentSession.Provider))
+		if currentSession.Persona != "" {
+			content.WriteString(fmt.Sprintf("[cyan][white] 🎭 %-12s[cyan][white]\n", currentSession.Persona))
+		}
+		if currentSession.Tools {
+			content.WriteString("[cyan][white] 🔧 Tools on[cyan][white]\n")
+		}
+		if currentSession.JSONMode {
+			mode := "JSON mode"
+			if len(currentSession.JSONSchema) > 0 {
+				mode += " + schema"
+			}
+			content.WriteString(fmt.Sprintf("[cyan][white] 🧾 %s[cyan][white]\n", mode))
+		}
+		params := ml.app.generationParams()
+		if summary := paramsSummary(&params); summary != "" {
+			content.WriteString(fmt.Sprintf("[cyan][white] ⚙️  %s[cyan][white]\n", summary))
+		}
+		if ml.app.modelListModal.isUnavailable(currentSession.Provider, currentSession.Model) {
+			content.WriteString("[red] 🚫 Unavailable[white]\n")
+		}
 		content.WriteString(fmt.Sprintf("\n\n"))
 	}
 
@@ -392,10 +698,7 @@ func (ml *MainLayout) updateSidebar() {
 	if currentSession != nil {
 		content.WriteString(fmt.Sprintf("[magenta][white] Started: %s[magenta][white]\n", currentSession.CreatedAt.Format("15:04")))
 		if currentSession.Title != "" && len(currentSession.Title) > 0 {
-			title := currentSession.Title
-			if len(title) > 15 {
-				title = title[:12] + "..."
-			}
+			title := truncateRunes(currentSession.Title, 14)
 			content.WriteString(fmt.Sprintf("[magenta][white] Title: %-9s[magenta][white]\n", title))
 		}
 	}
@@ -408,21 +711,61 @@ func (ml *MainLayout) updateSidebar() {
 	// Quick Tips
 	content.WriteString("[white]💡 SHORTCUTS[white]\n")
 	content.WriteString("[white][yellow] Enter[white] - Send msg     [white]\n")
+	content.WriteString("[white][yellow] Esc[white] - Cancel reply   [white]\n")
+	content.WriteString("[white][yellow] Ctrl+R[white] - Retry/regen  [white]\n")
 	content.WriteString("[white][yellow] Ctrl+C[white] - Copy text   [white]\n")
 	content.WriteString("[white][yellow] Ctrl+V[white] - Paste text  [white]\n")
 	content.WriteString("[white][yellow] Ctrl+O[white] - Chat history [white]\n")
 	content.WriteString("[white][yellow] Ctrl+-[white] - Change model [white]\n")
+	content.WriteString("[white][yellow] Ctrl+P[white] - Settings     [white]\n")
 	content.WriteString("[white][yellow] Ctrl+N[white] - New chat     [white]\n")
+	content.WriteString("[white][yellow] /system[white] - Sys prompt [white]\n")
+	content.WriteString("[white][yellow] /tools[white] - Toggle tools [white]\n")
+	content.WriteString("[white][yellow] /json[white] - JSON replies  [white]\n")
+	content.WriteString("[white][yellow] /compare[white] - Compare   [white]\n")
+	content.WriteString("[white][yellow] /profile[white] - Endpoint  [white]\n")
+	content.WriteString("[white][yellow] /image[white] - Attach image [white]\n")
+	content.WriteString("[white][yellow] /audio[white] - Voice input  [white]\n")
 	content.WriteString("[white][yellow] Ctrl+H[white] - Help menu    [white]\n")
 	content.WriteString("[white][yellow] PgUp/Dn[white] - Scroll chat [white]\n")
 	content.WriteString("[white][yellow] ↑/↓[white] - Line scroll    [white]\n")
+	content.WriteString("[white][yellow] Tab[white] - Focus messages [white]\n")
+	content.WriteString("[white][yellow] j/k[white] - Select message [white]\n")
+	content.WriteString("[white][yellow] p[white] - Pin message      [white]\n")
+	content.WriteString("[white][yellow] < >[white] - Switch version [white]\n")
+	content.WriteString("[white][yellow] e[white] - Edit & branch    [white]\n")
+	content.WriteString("[white][yellow] h/l[white] - Switch branch  [white]\n")
+	content.WriteString("[white][yellow] t[white] - Show reasoning   [white]\n")
 	content.WriteString("\n\n")
 
 	ml.sidebar.SetText(content.String())
 }
 
 func (ml *MainLayout) updateStatus(status string) {
-	ml.statusBar.SetText(status)
+	ml.status = status
+	ml.statusBar.SetText(status + ml.quota)
+}
+
+// updateRateLimit shows the provider's remaining request and token quota
+// alongside the status message.
+func (ml *MainLayout) updateRateLimit(providerName string, limit provider.RateLimit) {
+	var parts []string
+	if limit.LimitRequests > 0 {
+		parts = append(parts, fmt.Sprintf("req %d/%d", limit.RemainingRequests, limit.LimitRequests))
+	}
+	if limit.LimitTokens > 0 {
+		parts = append(parts, fmt.Sprintf("tok %d/%d", limit.RemainingTokens, limit.LimitTokens))
+	}
+	if len(parts) == 0 {
+		return
+	}
+
+	color := "gray"
+	if (limit.LimitRequests > 0 && limit.RemainingRequests == 0) || (limit.LimitTokens > 0 && limit.RemainingTokens == 0) {
+		color = "red"
+	}
+	ml.quota = fmt.Sprintf("  [%s]│ 📉 %s quota: %s", color, providerName, strings.Join(parts, " • "))
+	ml.updateStatus(ml.status)
 }
 
 // Copy functionality
@@ -1,9 +1,11 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"time"
 
-	"github.com/Rohan-Shah-312003/tui-gpt/internal/groq"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -11,6 +13,23 @@ import (
 type ModelListModal struct {
 	app       *App
 	modelList *tview.List
+	entries   []modelEntry
+	failures  []string
+	loading   bool
+}
+
+// modelListTimeout bounds how long a single provider may take to list its
+// models, so an unreachable local server does not stall the picker.
+const modelListTimeout = 5 * time.Second
+
+// defaultContextWindow is assumed for models whose provider does not report
+// a context length.
+const defaultContextWindow = 8192
+
+// modelEntry is one selectable model together with the provider serving it.
+type modelEntry struct {
+	provider string
+	model    provider.Model
 }
 
 func NewModelListModal(app *App) *ModelListModal {
@@ -76,30 +95,137 @@ func (mlm *ModelListModal) setupInputCapture() {
 	})
 }
 
-func (mlm *ModelListModal) Show() {
-	models := groq.GetAvailableModels()
-	currentModel := groq.GetCurrentModel()
+// refresh lists the models of every registered provider in the background
+// and redraws the list once they arrive. Providers that fail are reported
+// below the list instead of hiding the others.
+func (mlm *ModelListModal) refresh() {
+	var entries []modelEntry
+	var failures []string
+	for _, p := range provider.All() {
+		ctx, cancel := context.WithTimeout(context.Background(), modelListTimeout)
+		models, err := p.ListModels(ctx)
+		cancel()
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", p.Name(), err))
+			continue
+		}
+		for _, model := range models {
+			entries = append(entries, modelEntry{provider: p.Name(), model: model})
+		}
+	}
+
+	mlm.app.app.QueueUpdateDraw(func() {
+		mlm.entries = entries
+		mlm.failures = failures
+		mlm.loading = false
+		mlm.populate()
+		mlm.app.mainLayout.updateSidebar()
+	})
+}
+
+// populate fills the list from the last loaded entries, keeping the cursor
+// where it was.
+func (mlm *ModelListModal) populate() {
+	session := mlm.app.GetCurrentSession()
+	current := mlm.modelList.GetCurrentItem()
 	mlm.modelList.Clear()
 
-	modelKeys := []string{
-		"llama3-70b-8192",
-		"llama3-8b-8192",
-		"mixtral-8x7b-32768",
-		"gemma-7b-it",
-		"llama3-groq-70b-8192-tool-use-preview",
-		"llama3-groq-8b-8192-tool-use-preview",
-	}
-
-	for _, key := range modelKeys {
-		if name, exists := models[key]; exists {
-			mainText := name
-			if key == currentModel {
-				mainText = "✅ " + name + " (Current)"
-			}
-			secondaryText := key
-			mlm.modelList.AddItem(mainText, secondaryText, 0, nil)
+	if len(mlm.entries) == 0 && mlm.loading {
+		mlm.modelList.AddItem("⏳ Loading models...", "Asking each provider for its models", 0, nil)
+		return
+	}
+
+	for _, entry := range mlm.entries {
+		mainText := entry.model.Name
+		if entry.model.Unavailable {
+			mainText = "[gray]🚫 " + entry.model.Name + " (Unavailable)"
+		}
+		if entry.provider == session.Provider && provider.SameModel(entry.model.ID, session.Model) {
+			mainText = "✅ " + mainText + " (Current)"
+		}
+		secondaryText := fmt.Sprintf("%s • %s", entry.provider, entry.model.ID)
+		if entry.model.Vision {
+			secondaryText += " • 🖼️ vision"
+		}
+		mlm.modelList.AddItem(mainText, secondaryText, 0, nil)
+	}
+	for _, failure := range mlm.failures {
+		mlm.modelList.AddItem("[red]⚠️  Provider unavailable", failure, 0, nil)
+	}
+
+	if current < mlm.modelList.GetItemCount() {
+		mlm.modelList.SetCurrentItem(current)
+	}
+}
+
+// displayName returns the human-readable name of a model, falling back to
+// its ID when the model is not known.
+func (mlm *ModelListModal) displayName(providerName, modelID string) string {
+	for _, entry := range mlm.entries {
+		if entry.provider == providerName && provider.SameModel(entry.model.ID, modelID) {
+			return entry.model.Name
+		}
+	}
+	return modelID
+}
+
+// contextWindow returns the context length of a model in tokens, or
+// defaultContextWindow when its provider does not say.
+func (mlm *ModelListModal) contextWindow(providerName, modelID string) int {
+	for _, entry := range mlm.entries {
+		if entry.provider == providerName && provider.SameModel(entry.model.ID, modelID) && entry.model.ContextWindow > 0 {
+			return entry.model.ContextWindow
+		}
+	}
+	return defaultContextWindow
+}
+
+// supportsVision reports whether a model accepts images. Models missing
+// from the list are judged by their ID.
+func (mlm *ModelListModal) supportsVision(providerName, modelID string) bool {
+	chatProvider, err := provider.Get(providerName)
+	if err != nil || !chatProvider.Capabilities().Vision {
+		return false
+	}
+	for _, entry := range mlm.entries {
+		if entry.provider == providerName && provider.SameModel(entry.model.ID, modelID) {
+			return entry.model.Vision
+		}
+	}
+	return provider.IsVisionModel(modelID)
+}
+
+// cheapestModel returns the listed model of a provider that likely costs
+// the least, or its default model when none is known to be cheap.
+func (mlm *ModelListModal) cheapestModel(chatProvider provider.Provider) string {
+	var models []provider.Model
+	for _, entry := range mlm.entries {
+		if entry.provider == chatProvider.Name() {
+			models = append(models, entry.model)
+		}
+	}
+	if model, ok := provider.CheapestModel(models); ok {
+		return model
+	}
+	return chatProvider.DefaultModel()
+}
+
+// isUnavailable reports whether a model is known to be gone, as its
+// provider flagged it. Models missing from a listing are not judged, since
+// providers accept names they do not list, such as aliases.
+func (mlm *ModelListModal) isUnavailable(providerName, modelID string) bool {
+	for _, entry := range mlm.entries {
+		if entry.provider == providerName && provider.SameModel(entry.model.ID, modelID) {
+			return entry.model.Unavailable
 		}
 	}
+	return false
+}
+
+func (mlm *ModelListModal) Show() {
+	mlm.loading = true
+	mlm.populate()
+	go mlm.refresh()
 
 	mlm.app.pages.ShowPage("modellist")
 	mlm.app.isShowingModelList = true
@@ -112,29 +238,28 @@ func (mlm *ModelListModal) Hide() {
 }
 
 func (mlm *ModelListModal) selectModel(index int) {
-	modelKeys := []string{
-		"llama3-70b-8192",
-		"llama3-8b-8192",
-		"mixtral-8x7b-32768",
-		"gemma-7b-it",
-		"llama3-groq-70b-8192-tool-use-preview",
-		"llama3-groq-8b-8192-tool-use-preview",
-	}
-
-	if index < 0 || index >= len(modelKeys) {
+	if index < 0 || index >= len(mlm.entries) {
 		mlm.app.mainLayout.updateStatus("[red]❌ Invalid model selection")
 		return
 	}
 
-	selectedModel := modelKeys[index]
-	models := groq.GetAvailableModels()
-
-	if err := groq.SetModel(selectedModel); err != nil {
+	selected := mlm.entries[index]
+	if selected.model.Unavailable {
+		mlm.app.mainLayout.updateStatus(fmt.Sprintf("[red]🚫 %s is no longer available", selected.model.Name))
+		return
+	}
+	chatProvider, err := provider.Get(selected.provider)
+	if err != nil {
 		mlm.app.mainLayout.updateStatus(fmt.Sprintf("[red]❌ Failed to set model: %v", err))
 		return
 	}
+	if !chatProvider.Configured() {
+		mlm.app.mainLayout.updateStatus(fmt.Sprintf("[red]❌ %s is not configured - set its API key first", selected.provider))
+		return
+	}
+	mlm.app.setSessionModel(selected.provider, selected.model.ID)
 
-	mlm.app.mainLayout.updateStatus(fmt.Sprintf("[green]🤖 Model changed to: %s", models[selectedModel]))
+	mlm.app.mainLayout.updateStatus(fmt.Sprintf("[green]🤖 Model changed to: %s", selected.model.Name))
 	mlm.app.mainLayout.updateSidebar()
 
 	mlm.Hide()
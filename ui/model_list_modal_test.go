@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/anthropic"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/ollama"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/provider"
+)
+
+// The shipped default models are named differently from how their
+// providers list them, and must not be refused as unavailable.
+func TestDefaultModelsAreAvailable(t *testing.T) {
+	t.Setenv("OLLAMA_MODEL", "")
+	mlm := &ModelListModal{entries: []modelEntry{
+		{provider: "ollama", model: provider.Model{ID: "llama3.2:latest", ContextWindow: 131072}},
+		{provider: "ollama", model: provider.Model{ID: "qwen2.5:7b"}},
+		{provider: "anthropic", model: provider.Model{ID: "claude-sonnet-4-5-20250929", ContextWindow: 200000}},
+		{provider: "groq", model: provider.Model{ID: "gemma-7b-it", Unavailable: true}},
+	}}
+
+	defaults := []struct {
+		provider, model string
+		window          int
+	}{
+		{"ollama", ollama.NewClient().DefaultModel(), 131072},
+		{"anthropic", anthropic.NewClient().DefaultModel(), 200000},
+	}
+	for _, d := range defaults {
+		if mlm.isUnavailable(d.provider, d.model) {
+			t.Errorf("%s default %q is reported unavailable", d.provider, d.model)
+		}
+		if got := mlm.contextWindow(d.provider, d.model); got != d.window {
+			t.Errorf("%s default %q has a context window of %d, want %d", d.provider, d.model, got, d.window)
+		}
+	}
+
+	if mlm.isUnavailable("ollama", "mistral") {
+		t.Error("a model missing from the listing is reported unavailable")
+	}
+	if !mlm.isUnavailable("groq", "gemma-7b-it") {
+		t.Error("a model flagged by its provider is not reported unavailable")
+	}
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/config"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type PersonaModal struct {
+	app         *App
+	personaList *tview.List
+	personas    []config.Persona
+}
+
+func NewPersonaModal(app *App) *PersonaModal {
+	return &PersonaModal{
+		app:         app,
+		personaList: tview.NewList(),
+	}
+}
+
+func (pm *PersonaModal) Create() *tview.Flex {
+	pm.personaList.ShowSecondaryText(true).
+		SetHighlightFullLine(true).
+		SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+			pm.selectPersona(index)
+		})
+	pm.personaList.SetBorder(true).SetTitle(" Personas ").SetBorderColor(tcell.ColorDarkCyan)
+
+	instructions := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[yellow]🎭 New Chat\n\n[white]• Use ↑/↓ to navigate\n• Press Enter to start a chat with the persona\n• Press Escape to close").
+		SetTextAlign(tview.AlignLeft)
+	instructions.SetBorder(true).SetTitle(" Instructions ").SetBorderColor(tcell.ColorGreen)
+
+	personaListLayout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(instructions, 6, 1, false).
+		AddItem(pm.personaList, 0, 1, true)
+
+	pm.personaList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			pm.Hide()
+			return nil
+		}
+		return event
+	})
+
+	return personaListLayout
+}
+
+// Show lists the personas from personas.json. The first entry starts a
+// plain chat without a persona.
+func (pm *PersonaModal) Show(personas []config.Persona) {
+	pm.personas = personas
+
+	pm.personaList.Clear()
+	pm.personaList.AddItem("💬 Default", "No system prompt", 0, nil)
+	for _, persona := range personas {
+		secondaryText := persona.SystemPrompt
+		if persona.Model != "" {
+			secondaryText = fmt.Sprintf("%s • %s", persona.Model, secondaryText)
+		}
+		pm.personaList.AddItem("🎭 "+persona.Name, secondaryText, 0, nil)
+	}
+
+	pm.app.pages.ShowPage("personalist")
+	pm.app.isShowingPersonaList = true
+	pm.app.app.SetFocus(pm.personaList)
+}
+
+func (pm *PersonaModal) Hide() {
+	pm.app.pages.HidePage("personalist")
+	pm.app.isShowingPersonaList = false
+	pm.app.app.SetFocus(pm.app.mainLayout.inputField)
+}
+
+func (pm *PersonaModal) selectPersona(index int) {
+	pm.Hide()
+	if index <= 0 || index > len(pm.personas) {
+		pm.app.startChat(nil)
+		return
+	}
+	persona := pm.personas[index-1]
+	pm.app.startChat(&persona)
+}
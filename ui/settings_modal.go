@@ -0,0 +1,265 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/config"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/history"
+	"github.com/Rohan-Shah-312003/tui-gpt/internal/storage"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Settings scopes, in the order of the scope drop-down
+const (
+	scopeChat = iota
+	scopeDefault
+)
+
+type SettingsModal struct {
+	app   *App
+	form  *tview.Form
+	scope int
+
+	temperature  *tview.InputField
+	topP         *tview.InputField
+	maxTokens    *tview.InputField
+	stop         *tview.InputField
+	seed         *tview.InputField
+	strategy     *tview.DropDown
+	autoTitle    *tview.Checkbox
+	autoContinue *tview.Checkbox
+}
+
+// strategyLabels names the context strategies in the settings drop-down,
+// after a first "Default" option that leaves the choice to the next level.
+var strategyLabels = map[string]string{
+	history.StrategyDropOldest: "Drop oldest messages",
+	history.StrategyKeepPinned: "Keep pinned messages",
+	history.StrategySummarize:  "Summarize older messages",
+}
+
+func NewSettingsModal(app *App) *SettingsModal {
+	return &SettingsModal{
+		app:  app,
+		form: tview.NewForm(),
+	}
+}
+
+func (sm *SettingsModal) Create() *tview.Flex {
+	sm.temperature = tview.NewInputField().SetLabel("Temperature (0-2)").SetFieldWidth(10)
+	sm.topP = tview.NewInputField().SetLabel("Top P (0-1)").SetFieldWidth(10)
+	sm.maxTokens = tview.NewInputField().SetLabel("Max tokens").SetFieldWidth(10)
+	sm.stop = tview.NewInputField().SetLabel("Stop (comma separated)").SetFieldWidth(30)
+	sm.seed = tview.NewInputField().SetLabel("Seed").SetFieldWidth(12)
+
+	strategies := []string{"Default"}
+	for _, strategy := range history.Strategies {
+		strategies = append(strategies, strategyLabels[strategy])
+	}
+	sm.strategy = tview.NewDropDown().SetLabel("When history is too long").SetOptions(strategies, nil)
+	sm.autoTitle = tview.NewCheckbox().SetLabel("Model-written titles (all chats)")
+	sm.autoContinue = tview.NewCheckbox().SetLabel("Continue cut-off replies (all chats)")
+
+	sm.form.AddDropDown("Apply to", []string{"This chat", "Default for all chats"}, scopeChat, func(option string, optionIndex int) {
+		sm.scope = optionIndex
+		sm.load()
+	}).
+		AddFormItem(sm.temperature).
+		AddFormItem(sm.topP).
+		AddFormItem(sm.maxTokens).
+		AddFormItem(sm.stop).
+		AddFormItem(sm.seed).
+		AddFormItem(sm.strategy).
+		AddFormItem(sm.autoTitle).
+		AddFormItem(sm.autoContinue).
+		AddButton("💾 Save", sm.save).
+		AddButton("🧹 Reset", sm.reset).
+		AddButton("❌ Close", sm.Hide).
+		SetCancelFunc(sm.Hide)
+	sm.form.SetBorder(true).SetTitle(" Generation Settings ").SetBorderColor(tcell.ColorDarkCyan)
+
+	instructions := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[yellow]⚙️  Generation Settings\n\n[white]• Leave a field empty to use the default\n• Chat settings override the defaults, which override the provider's\n• Long chats are trimmed to the model's context window as chosen below\n• The checkboxes apply to every chat\n• Press Escape to close").
+		SetTextAlign(tview.AlignLeft)
+	instructions.SetBorder(true).SetTitle(" Instructions ").SetBorderColor(tcell.ColorGreen)
+
+	return tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(instructions, 9, 1, false).
+		AddItem(sm.form, 0, 1, true)
+}
+
+// load fills the fields from the parameters of the selected scope.
+func (sm *SettingsModal) load() {
+	var params storage.GenerationParams
+	strategy := sm.app.currentSession.ContextStrategy
+	if sm.scope == scopeDefault {
+		params = sm.app.settings.Params
+		strategy = sm.app.settings.ContextStrategy
+	} else if sm.app.currentSession.Params != nil {
+		params = *sm.app.currentSession.Params
+	}
+
+	sm.temperature.SetText(formatFloatParam(params.Temperature))
+	sm.topP.SetText(formatFloatParam(params.TopP))
+	sm.maxTokens.SetText(formatIntParam(params.MaxTokens))
+	sm.stop.SetText(strings.Join(params.Stop, ", "))
+	sm.seed.SetText(formatIntParam(params.Seed))
+	sm.strategy.SetCurrentOption(slices.Index(history.Strategies, strategy) + 1)
+	sm.autoTitle.SetChecked(sm.app.settings.AutoTitle)
+	sm.autoContinue.SetChecked(sm.app.settings.AutoContinue)
+}
+
+func (sm *SettingsModal) reset() {
+	for _, field := range []*tview.InputField{sm.temperature, sm.topP, sm.maxTokens, sm.stop, sm.seed} {
+		field.SetText("")
+	}
+	sm.strategy.SetCurrentOption(0)
+}
+
+func (sm *SettingsModal) save() {
+	params, err := sm.parse()
+	if err != nil {
+		sm.app.mainLayout.updateStatus(fmt.Sprintf("[red]❌ %v", err))
+		return
+	}
+	strategy := ""
+	if index, _ := sm.strategy.GetCurrentOption(); index > 0 {
+		strategy = history.Strategies[index-1]
+	}
+
+	// The checkboxes are always global preferences, whatever the scope
+	settings := sm.app.settings
+	settings.AutoTitle = sm.autoTitle.IsChecked()
+	settings.AutoContinue = sm.autoContinue.IsChecked()
+	if sm.scope == scopeDefault {
+		settings.Params = params
+		settings.ContextStrategy = strategy
+	}
+	if sm.scope == scopeDefault || settings.AutoTitle != sm.app.settings.AutoTitle || settings.AutoContinue != sm.app.settings.AutoContinue {
+		if err := config.SaveSettings(settings); err != nil {
+			sm.app.mainLayout.updateStatus(fmt.Sprintf("[red]❌ Failed to save settings: %v", err))
+			return
+		}
+		sm.app.settings = settings
+	}
+
+	if sm.scope == scopeDefault {
+		sm.app.mainLayout.updateStatus("[green]⚙️  Default settings saved")
+	} else {
+		sm.app.currentSession.ContextStrategy = strategy
+		sm.app.currentSession.Params = nil
+		if !params.IsZero() {
+			sm.app.currentSession.Params = &params
+		}
+		sm.app.mainLayout.updateStatus("[green]⚙️  Chat settings updated")
+	}
+
+	sm.app.mainLayout.updateSidebar()
+	sm.Hide()
+}
+
+// parse reads and validates the fields. Empty fields are left unset.
+func (sm *SettingsModal) parse() (storage.GenerationParams, error) {
+	var params storage.GenerationParams
+	var err error
+
+	if params.Temperature, err = parseFloatParam("temperature", sm.temperature.GetText(), 0, 2); err != nil {
+		return params, err
+	}
+	if params.TopP, err = parseFloatParam("top_p", sm.topP.GetText(), 0, 1); err != nil {
+		return params, err
+	}
+	if params.MaxTokens, err = parseIntParam("max_tokens", sm.maxTokens.GetText()); err != nil {
+		return params, err
+	}
+	if params.MaxTokens != nil && *params.MaxTokens <= 0 {
+		return params, fmt.Errorf("max_tokens must be positive")
+	}
+	if params.Seed, err = parseIntParam("seed", sm.seed.GetText()); err != nil {
+		return params, err
+	}
+	for _, stop := range strings.Split(sm.stop.GetText(), ",") {
+		if stop = strings.TrimSpace(stop); stop != "" {
+			params.Stop = append(params.Stop, stop)
+		}
+	}
+	return params, nil
+}
+
+func (sm *SettingsModal) Show() {
+	sm.load()
+	sm.app.pages.ShowPage("settings")
+	sm.app.isShowingSettings = true
+	sm.app.app.SetFocus(sm.form)
+}
+
+func (sm *SettingsModal) Hide() {
+	sm.app.pages.HidePage("settings")
+	sm.app.isShowingSettings = false
+	sm.app.app.SetFocus(sm.app.mainLayout.inputField)
+}
+
+func parseFloatParam(name, text string, min, max float64) (*float64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || value < min || value > max {
+		return nil, fmt.Errorf("%s must be a number from %g to %g", name, min, max)
+	}
+	return &value, nil
+}
+
+func parseIntParam(name, text string) (*int, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a whole number", name)
+	}
+	return &value, nil
+}
+
+func formatFloatParam(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'g', -1, 64)
+}
+
+func formatIntParam(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+// paramsSummary is a compact description of the parameters a reply was
+// generated with.
+func paramsSummary(params *storage.GenerationParams) string {
+	if params == nil {
+		return ""
+	}
+	var parts []string
+	if params.Temperature != nil {
+		parts = append(parts, "temp "+formatFloatParam(params.Temperature))
+	}
+	if params.TopP != nil {
+		parts = append(parts, "top_p "+formatFloatParam(params.TopP))
+	}
+	if params.MaxTokens != nil {
+		parts = append(parts, "max "+formatIntParam(params.MaxTokens))
+	}
+	if params.Seed != nil {
+		parts = append(parts, "seed "+formatIntParam(params.Seed))
+	}
+	return strings.Join(parts, " • ")
+}
@@ -9,4 +9,7 @@
 // - help_modal.go: Help modal implementation
 // - chat_list_modal.go: Chat list modal implementation
 // - model_list_modal.go: Model selection modal implementation
+// - persona_modal.go: Persona picker shown when starting a new chat
+// - settings_modal.go: Generation parameter editor
+// - compare_modal.go: Side-by-side replies from several models
 package ui
\ No newline at end of file